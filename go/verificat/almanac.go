@@ -1,9 +1,12 @@
 package main
 
 import (
+	"bytes"
 	"encoding/json"
 	"fmt"
 	"io"
+	"sort"
+	"time"
 )
 
 // Almanac is used in the place of []WMService
@@ -19,6 +22,77 @@ func NewAlmanac(rdr io.Reader) ([]WMService, error) {
 	return almanac, err
 }
 
+// History is every stored verification run, oldest first.
+// The Almanac is the "latest" view derived from it.
+type History []VerifyResult
+
+// fsDatabase is the layout of the JSON database on disk.
+// Older databases are a bare Almanac array with no History.
+type fsDatabase struct {
+	Almanac Almanac
+	History History
+}
+
+// NewDatabase will take an io.Reader like /database/ and decode both the Almanac and its History.
+// A bare JSON array is read as an Almanac from before History was kept.
+func NewDatabase(rdr io.Reader) (Almanac, History, error) {
+	raw, err := io.ReadAll(rdr)
+	if err != nil {
+		return nil, nil, fmt.Errorf("problem reading database, %v", err)
+	}
+
+	if bytes.HasPrefix(bytes.TrimSpace(raw), []byte("[")) {
+		almanac, err := NewAlmanac(bytes.NewReader(raw))
+		return almanac, History{}, err
+	}
+
+	var db fsDatabase
+	if err := json.Unmarshal(raw, &db); err != nil {
+		return nil, nil, fmt.Errorf("problem parsing database, %v", err)
+	}
+	if db.History == nil {
+		db.History = History{}
+	}
+	return db.Almanac, db.History, nil
+}
+
+// Between returns the runs for a service with a Datetime inside [from, to].
+// A zero /from/ or /to/ leaves that end of the range open.
+func (h History) Between(name string, from, to time.Time) []VerifyResult {
+	var runs []VerifyResult
+	for _, r := range h {
+		if r.Service != name {
+			continue
+		}
+		if !from.IsZero() && r.Datetime < from.Unix() {
+			continue
+		}
+		if !to.IsZero() && r.Datetime > to.Unix() {
+			continue
+		}
+		runs = append(runs, r)
+	}
+	return runs
+}
+
+// Copy returns an Almanac that shares nothing with this one.
+func (l Almanac) Copy() Almanac {
+	if l == nil {
+		return nil
+	}
+	c := make(Almanac, len(l))
+	for i, service := range l {
+		c[i] = service
+		if service.Principles != nil {
+			c[i].Principles = make(PrincipleScores, len(service.Principles))
+			for p, score := range service.Principles {
+				c[i].Principles[p] = score
+			}
+		}
+	}
+	return c
+}
+
 // Find takes an Almanac and searches for a service name
 func (l Almanac) Find(name string) *WMService {
 	for i, p := range l {
@@ -28,3 +102,52 @@ func (l Almanac) Find(name string) *WMService {
 	}
 	return nil
 }
+
+// SystemView is a System's latest result alongside the latest results of its parts,
+// the Components, Resources and APIs that belong to it.
+// Unverified are the parts in the catalog that have no result yet.
+// Rollup is the System's readiness derived from all of them, once a RollupConfig is applied.
+type SystemView struct {
+	System     WMService
+	Parts      Almanac
+	Unverified []string `json:",omitempty"`
+	Rollup     *Rollup  `json:",omitempty"`
+}
+
+// SystemView gathers a System and its parts from the Almanac.
+// The parts are the System's members in the catalog /g/, which follows subcomponentOf and dependsOn,
+// and whatever was recorded as part of the System when it was verified.
+// Without a catalog, the recorded System is all there is.
+// A System that hasn't been verified itself is still shown when any of its parts have been.
+func (l Almanac) SystemView(name string, g *DependencyGraph) (SystemView, bool) {
+	view := SystemView{System: WMService{Name: name, Kind: KindSystem}, Parts: Almanac{}}
+	found := false
+
+	members := make(map[string]bool)
+	if g != nil {
+		for _, part := range g.partsOf(name) {
+			members[part] = true
+		}
+	}
+
+	for _, service := range l {
+		switch {
+		case service.Name == name && (service.Kind == "" || service.Kind == KindSystem):
+			view.System = service
+			found = true
+		case service.Kind != KindSystem && (service.System == name || members[service.Name]):
+			view.Parts = append(view.Parts, service)
+			delete(members, service.Name)
+			found = true
+		}
+	}
+	sort.Slice(view.Parts, func(i, j int) bool {
+		return view.Parts[i].Name < view.Parts[j].Name
+	})
+
+	for part := range members {
+		view.Unverified = append(view.Unverified, part)
+	}
+	sort.Strings(view.Unverified)
+	return view, found
+}
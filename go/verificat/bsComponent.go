@@ -0,0 +1,21 @@
+package main
+
+import (
+	"log/slog"
+
+	"github.com/tdabasinskas/go-backstage/v2/backstage"
+)
+
+// BSCE is shorthand for the backstage.ComponentEntityV1alpha1 type (which is a struct)
+type BSCE *backstage.ComponentEntityV1alpha1
+
+// ReadComponentBS takes a weedmaps service and returns the catalog from Backstage
+func ReadComponentBS(wms string, c Catalog) (BSCE, error) {
+	component, err := c.GetComponent(wms)
+	if err != nil {
+		slog.Error("Failed to fetch Component name from Backstage", slog.Any("Error", err))
+		return nil, err
+	}
+
+	return component, nil
+}
@@ -0,0 +1,42 @@
+package main
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+// Can we see all elements of every component entity?
+func TestReadComponentBS(t *testing.T) {
+	c, err := NewBackstageCatalog(newFakeBackstage(t, fakeBackstageFixtures))
+	assertError(t, err, nil)
+
+	readTests := []struct {
+		Name    string
+		Service string
+		Expect  string
+	}{
+		{"Admin", "admin", "code-owners-admin"},
+		{"CoreBraze", "core-braze", "code-owners-core"},
+		{"AdServer", "ad-server-cache-builder", "code-owners-wasp"},
+	}
+
+	for _, tt := range readTests {
+		component, err := ReadComponentBS(tt.Service, c)
+		assertError(t, err, nil)
+		got := component.Spec.Owner
+		want := tt.Expect
+		if diff := cmp.Diff(got, want); diff != "" {
+			t.Error(diff)
+			t.Errorf("For '%v' the component looks like\n: %v", tt.Service, component)
+		}
+	}
+
+	t.Run("Receives error for an unknown component", func(t *testing.T) {
+		component, err := ReadComponentBS("Revolution", c)
+		assertError(t, err, CatalogNotFound)
+		if component != nil {
+			t.Errorf("got %v want no component", component)
+		}
+	})
+}
@@ -1,9 +1,9 @@
 package main
 
 import (
-	"context"
 	"errors"
 	"log/slog"
+	"strings"
 
 	"github.com/tdabasinskas/go-backstage/v2/backstage"
 )
@@ -13,8 +13,13 @@ type BSSE *backstage.SystemEntityV1alpha1
 
 var SystemNotRecognized = errors.New("system not recognized")
 
+const (
+	ghOrg       = "GhostGroup"              // The GitHub organization Systems live in by default
+	projectSlug = "github.com/project-slug" // Backstage annotation naming a System's GitHub repository
+)
+
 // ReadSystemBS takes a weedmaps service and returns the service owner
-func ReadSystemBS(wms string, c *backstage.Client) (string, BSSE, error) {
+func ReadSystemBS(wms string, c Catalog) (string, BSSE, error) {
 	// first get a list of systems
 	services, err := bsSystemList(c)
 	if err != nil {
@@ -23,42 +28,49 @@ func ReadSystemBS(wms string, c *backstage.Client) (string, BSSE, error) {
 
 	// make sure the requested test belongs in the list
 	// if it doesn't belong, the test will appear zeroed out.
-	for _, service := range services {
-		if wms == service {
-			// When there is a match with the System List,
-			// grab the System Entity itself and get the Owner.
-			se, _, err := c.Catalog.Systems.Get(context.Background(), wms, "")
-			if err != nil {
-				slog.Error("Failed to fetch System", slog.Any("Error", err))
-				return "", nil, err
-			}
-			owner := se.Spec.Owner
-			slog.Info("Owner Found", slog.String("Owner", owner))
-			return owner, se, err
-		}
+	if !services[wms] {
+		slog.Error("Failed to fetch System", slog.Any("Error", SystemNotRecognized))
+		return "", nil, SystemNotRecognized
 	}
 
-	// If we've gotten this far, there wasn't a match.
-	slog.Error("Failed to fetch System", slog.Any("Error", SystemNotRecognized))
-	return "", nil, SystemNotRecognized
+	// When there is a match with the System List,
+	// grab the System Entity itself and get the Owner.
+	se, err := c.GetSystem(wms)
+	if err != nil {
+		slog.Error("Failed to fetch System", slog.Any("Error", err))
+		return "", nil, err
+	}
+	owner := se.Spec.Owner
+	slog.Info("Owner Found", slog.String("Owner", owner))
+	return owner, se, err
 }
 
-// bsSystemList queries Backstage for a list of all System definitions ("kind=system") and returns an array populated with the list.
-// TODO: Make this a map, key = backstage system / value = github repo link
-// This way, we can check the real repo for CODEOWNERS instead of defaulting to the 'service name' (which works for some, but not all)
-// This is: .Metadata.Annotations.github.com/project-slug (e.g.: for `core` this is `GhostGroup/weedmaps`)
-func bsSystemList(c *backstage.Client) ([]string, error) {
-	var s []string
+// bsSystemList queries Backstage for a list of all System definitions ("kind=system")
+// and returns the set of their names.
+func bsSystemList(c Catalog) (map[string]bool, error) {
+	s := make(map[string]bool)
 
-	if systems, _, err := c.Catalog.Entities.List(context.Background(), &backstage.ListEntityOptions{Filters: []string{"kind=system"}}); err != nil {
+	systems, err := c.ListSystems()
+	if err != nil {
 		slog.Error("Failed to get System List from Backstage", slog.Any("Error", err))
-		s = append(s, "")
-		return s, err
-	} else {
-		for _, e := range systems {
-			s = append(s, e.Metadata.Name)
-		}
-		slog.Debug("System List Found", slog.Any("Systems", s))
 		return s, err
 	}
+
+	for _, e := range systems {
+		s[e.Metadata.Name] = true
+	}
+	slog.Debug("System List Found", slog.Any("Systems", s))
+	return s, err
+}
+
+// entityRepos lists the GitHub repositories an entity's code may live in, best guess first:
+// the github.com/project-slug annotation, then a repository named after the entity.
+func entityRepos(m backstage.EntityMeta) []string {
+	fallback := ghOrg + "/" + m.Name
+
+	slug := strings.Trim(m.Annotations[projectSlug], "/ ")
+	if slug == "" || slug == fallback {
+		return []string{fallback}
+	}
+	return []string{slug, fallback}
 }
@@ -1,37 +1,31 @@
 package main
 
 import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
 	"testing"
 
 	"github.com/google/go-cmp/cmp"
 	"github.com/tdabasinskas/go-backstage/v2/backstage"
 )
 
-/*
-Integration Testing for Backstage
-*/
-const (
-	backstageURL = "https://backstage.internal-weedmaps.com"
-)
-
-// TODO: A unit test version of this could use a fake BSSE object.
-//
 // Can we see match elements of each service entity?
 func TestReadSystemBS(t *testing.T) {
+	c, err := NewBackstageCatalog(newFakeBackstage(t, fakeBackstageFixtures))
+	assertError(t, err, nil)
+
 	t.Run("reads Backstage catalog and matches system names", func(t *testing.T) {
-		c, err := backstage.NewClient(backstageURL, "default", nil)
-		assertError(t, err, nil)
 
 		readTests := []struct {
 			Name    string
 			Service string
 			Expect  string
-			Client  *backstage.Client
 		}{
-			{"Admin", "admin", "code-owners-admin", c},
-			{"Core", "core", "code-owners-core", c},
-			{"AdServer", "ad-server", "code-owners-wasp", c},
-			{"WeedmapsAPI", "weedmaps-api", "code-owners-api", c},
+			{"Admin", "admin", "code-owners-admin"},
+			{"Core", "core", "code-owners-core"},
+			{"AdServer", "ad-server", "code-owners-wasp"},
+			{"WeedmapsAPI", "weedmaps-api", "code-owners-api"},
 		}
 
 		for _, tt := range readTests {
@@ -47,18 +41,14 @@ func TestReadSystemBS(t *testing.T) {
 
 	// Test that the SystemNotRecognized error is thrown
 	t.Run("Handles only Systems it knows about", func(t *testing.T) {
-		c, err := backstage.NewClient(backstageURL, "default", nil)
-		assertError(t, err, nil)
-
 		readTests := []struct {
 			Name    string
 			Service string
 			Expect  string
-			Client  *backstage.Client
 		}{
-			{"Core-App", "core-app", "", c},
-			{"Prince", "Revolution", "", c},
-			{"Rubidium-Strontium", "Isochron", "", c},
+			{"Core-App", "core-app", ""},
+			{"Prince", "Revolution", ""},
+			{"Rubidium-Strontium", "Isochron", ""},
 		}
 
 		for _, tt := range readTests {
@@ -72,11 +62,64 @@ func TestReadSystemBS(t *testing.T) {
 		}
 	})
 
-	// Use a bad client value to trigger an error
-	/*
-		t.Run("Receives error with bad client", func(t *testing.T) {
-			_, err := backstage.NewClient(backstageURL, "default", nil)
-			assertNoError(t, err)
+	// A catalog that can't be read is an error, not an unrecognized System
+	t.Run("Receives error when Backstage fails", func(t *testing.T) {
+		broken := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			http.Error(w, `{"error": {"name": "InternalServerError"}}`, http.StatusInternalServerError)
+		}))
+		defer broken.Close()
+
+		c, err := NewBackstageCatalog(broken.URL)
+		assertError(t, err, nil)
+
+		owner, _, err := ReadSystemBS("admin", c)
+		if err == nil || errors.Is(err, SystemNotRecognized) || owner != "" {
+			t.Errorf("got %q, %v want an error from Backstage", owner, err)
+		}
+	})
+}
+
+// Where an entity's code lives comes from its project-slug annotation
+func TestEntityRepos(t *testing.T) {
+	repoTests := []struct {
+		Name        string
+		System      string
+		Annotations map[string]string
+		Expect      []string
+	}{
+		{"No annotation", "admin", nil, []string{"GhostGroup/admin"}},
+		{"Slug elsewhere", "core", map[string]string{projectSlug: "GhostGroup/weedmaps"}, []string{"GhostGroup/weedmaps", "GhostGroup/core"}},
+		{"Slug matches the name", "admin", map[string]string{projectSlug: "GhostGroup/admin"}, []string{"GhostGroup/admin"}},
+		{"Blank slug", "admin", map[string]string{projectSlug: " "}, []string{"GhostGroup/admin"}},
+	}
+
+	for _, tt := range repoTests {
+		t.Run(tt.Name, func(t *testing.T) {
+			got := entityRepos(backstage.EntityMeta{Name: tt.System, Annotations: tt.Annotations})
+			if diff := cmp.Diff(got, tt.Expect); diff != "" {
+				t.Error(diff)
+			}
 		})
-	*/
+	}
+}
+
+// The System List names every System in the catalog
+func TestBSSystemList(t *testing.T) {
+	url := newFakeBackstage(t, fakeBackstageFixtures)
+	c, err := NewBackstageCatalog(url)
+	assertError(t, err, nil)
+
+	got, err := bsSystemList(c)
+	assertError(t, err, nil)
+
+	want := map[string]bool{"admin": true, "core": true, "ad-server": true, "weedmaps-api": true}
+	if diff := cmp.Diff(got, want); diff != "" {
+		t.Error(diff)
+	}
+
+	names, err := ListSystems(url)
+	assertError(t, err, nil)
+	if diff := cmp.Diff(names, []string{"ad-server", "admin", "core", "weedmaps-api"}); diff != "" {
+		t.Error(diff)
+	}
 }
@@ -2,6 +2,7 @@ package main
 
 import (
 	"log/slog"
+	"slices"
 	"time"
 
 	"github.com/tdabasinskas/go-backstage/v2/backstage"
@@ -14,29 +15,133 @@ type SvcCat interface {
 
 // SvcConfig is the Client Configuration
 type SvcConfig struct {
-	URL      string // URL is the Backstage API endpoint
-	Service  string // Each Service is known as the "Component" in Backstage
-	Datetime int64  // Unix Epoch in seconds
-	Owner    string // Should equal CODEOWNERS for this repo in GitHub
+	URL       string                // URL is the Backstage API endpoint
+	Service   string                // A System by name, or another kind as kind:name, e.g.: component:core-braze
+	Kind      string                // The entity kind, e.g.: system or component
+	System    string                // The System a Component, Resource or API belongs to
+	Lifecycle string                // The spec.lifecycle of a Component or API, e.g.: production
+	Type      string                // The spec.type of a Component, Resource or API, e.g.: service
+	Datetime  int64                 // Unix Epoch in seconds
+	Owner     string                // Should equal CODEOWNERS for this repo in GitHub
+	Repos     []string              // GitHub repositories the Service may live in, best guess first
+	Metadata  *backstage.EntityMeta // The entity's metadata, e.g.: its description and annotations
 }
 
 // ReadSvc can query Backstage for a chunk of data about a System,
-// i.e. the "top-level" Weedmaps Service.
+// i.e. the "top-level" Weedmaps Service, or one of its Components, Resources or APIs.
 // Each method called for filling in data adds the entry to the SvcConfig struct.
 func (sc *SvcConfig) ReadSvc() (string, error) {
 	sc.Datetime = time.Now().Unix()
-	c, _ := backstage.NewClient(sc.URL, "default", nil)
+	ref, err := ParseEntityRef(sc.Service)
+	if err != nil {
+		return "", err
+	}
+	sc.Kind = ref.Kind
 
-	// We only want the owner here, not the entire system struct
-	owner, _, err := ReadSystemBS(sc.Service, c)
+	c, err := NewBackstageCatalog(sc.URL)
+	if err != nil {
+		return "", err
+	}
+
+	if ref.Kind != KindSystem {
+		err = sc.readPart(ref, c)
+		slog.Debug("Owner Set", slog.String("Owner", sc.Owner), slog.String("System", sc.System), slog.Any("Repos", sc.Repos))
+		return sc.Owner, err
+	}
+
+	// We only want the owner and where its code lives, not the entire system struct
+	owner, se, err := ReadSystemBS(ref.Name, c)
 	sc.Owner = owner
-	slog.Debug("Owner Set", slog.String("Owner", sc.Owner))
+	if se != nil {
+		sc.Repos = entityRepos(se.Metadata)
+		sc.Metadata = &se.Metadata
+	}
+	slog.Debug("Owner Set", slog.String("Owner", sc.Owner), slog.Any("Repos", sc.Repos))
 	return sc.Owner, err
 }
 
+// readPart reads the owner, parent System, lifecycle and type of a Component, Resource or API.
+// Parts often live in their System's repository (e.g.: core-braze is in GhostGroup/weedmaps),
+// so the System's repositories are tried after the part's own.
+func (sc *SvcConfig) readPart(ref EntityRef, c Catalog) error {
+	var meta backstage.EntityMeta
+	switch ref.Kind {
+	case KindComponent:
+		ce, err := ReadComponentBS(ref.Name, c)
+		if err != nil {
+			return err
+		}
+		meta = ce.Metadata
+		if ce.Spec != nil {
+			sc.Owner, sc.System = ce.Spec.Owner, entityRefName(ce.Spec.System)
+			sc.Lifecycle, sc.Type = ce.Spec.Lifecycle, ce.Spec.Type
+		}
+	case KindResource:
+		re, err := c.GetResource(ref.Name)
+		if err != nil {
+			slog.Error("Failed to fetch Resource", slog.Any("Error", err))
+			return err
+		}
+		meta = re.Metadata
+		if re.Spec != nil {
+			sc.Owner, sc.System = re.Spec.Owner, entityRefName(re.Spec.System)
+			sc.Type = re.Spec.Type
+		}
+	case KindAPI:
+		ae, err := c.GetAPI(ref.Name)
+		if err != nil {
+			slog.Error("Failed to fetch API", slog.Any("Error", err))
+			return err
+		}
+		meta = ae.Metadata
+		if ae.Spec != nil {
+			sc.Owner, sc.System = ae.Spec.Owner, entityRefName(ae.Spec.System)
+			sc.Lifecycle, sc.Type = ae.Spec.Lifecycle, ae.Spec.Type
+		}
+	}
+
+	sc.Repos = entityRepos(meta)
+	sc.Metadata = &meta
+	if sc.System == "" {
+		return nil
+	}
+	se, err := c.GetSystem(sc.System)
+	if err != nil {
+		// The part can still be verified from its own repository
+		slog.Warn("Parent System Not Found", slog.String("System", sc.System), slog.Any("Error", err))
+		return nil
+	}
+	for _, repo := range entityRepos(se.Metadata) {
+		if !slices.Contains(sc.Repos, repo) {
+			sc.Repos = append(sc.Repos, repo)
+		}
+	}
+	return nil
+}
+
 // ReadinessRead is the function that tests this service for Production Readiness
 func ReadinessRead(i SvcCat) (string, error) {
 	// Calling ReadSvc() initiates the source data struct, SvcConfig
 	// Currently only returning the Owner, which is what ReadSvc() returns
 	return i.ReadSvc()
 }
+
+// ListSystems returns the name of every System in the Backstage catalog at /url/.
+// This is how the Scheduler finds everything there is to verify.
+func ListSystems(url string) ([]string, error) {
+	c, err := NewBackstageCatalog(url)
+	if err != nil {
+		return nil, err
+	}
+	systems, err := bsSystemList(c)
+	if err != nil {
+		return nil, err
+	}
+
+	names := make([]string, 0, len(systems))
+	for name := range systems {
+		names = append(names, name)
+	}
+	slices.Sort(names)
+	return names, nil
+}
@@ -2,6 +2,7 @@ package main
 
 import (
 	"errors"
+	"slices"
 	"testing"
 )
 
@@ -49,3 +50,63 @@ func assertString(t testing.TB, got, want string) {
 		t.Errorf("got %q want %q", got, want)
 	}
 }
+
+// ReadSvc fills in the owner and repositories of a System from the catalog
+func TestReadSvc(t *testing.T) {
+	sc := &SvcConfig{URL: newFakeBackstage(t, fakeBackstageFixtures), Service: "core"}
+
+	got, err := ReadinessRead(sc)
+	assertError(t, err, nil)
+	assertString(t, got, "code-owners-core")
+
+	if len(sc.Repos) != 2 || sc.Repos[0] != "GhostGroup/weedmaps" {
+		t.Errorf("got repositories %v want GhostGroup/weedmaps first", sc.Repos)
+	}
+	if sc.Datetime == 0 {
+		t.Error("Datetime was not set")
+	}
+	if sc.Metadata == nil || sc.Metadata.Description != "The Weedmaps monolith" {
+		t.Errorf("got metadata %+v want core's", sc.Metadata)
+	}
+
+	// Parts are read from their own kind, and also look in their System's repositories
+	partTests := []struct {
+		Service   string
+		Owner     string
+		System    string
+		Lifecycle string
+		Type      string
+		Repos     []string
+	}{
+		{"component:core-braze", "code-owners-core", "core", "production", "service", []string{"GhostGroup/core-braze", "GhostGroup/weedmaps", "GhostGroup/core"}},
+		{"resource:core-db", "group:default/code-owners-core", "core", "", "database", []string{"GhostGroup/core-db", "GhostGroup/weedmaps", "GhostGroup/core"}},
+		{"api:ad-server-api", "code-owners-wasp", "ad-server", "production", "openapi", []string{"GhostGroup/ad-server", "GhostGroup/ad-server-api"}},
+	}
+	for _, tt := range partTests {
+		t.Run(tt.Service, func(t *testing.T) {
+			part := &SvcConfig{URL: sc.URL, Service: tt.Service}
+			got, err := ReadinessRead(part)
+			assertError(t, err, nil)
+			assertString(t, got, tt.Owner)
+			assertString(t, part.System, tt.System)
+			assertString(t, part.Lifecycle, tt.Lifecycle)
+			assertString(t, part.Type, tt.Type)
+			if ref, _ := ParseEntityRef(tt.Service); part.Metadata == nil || part.Metadata.Name != ref.Name {
+				t.Errorf("got metadata %+v want the part's own", part.Metadata)
+			}
+			if !slices.Equal(part.Repos, tt.Repos) {
+				t.Errorf("got repositories %v want %v", part.Repos, tt.Repos)
+			}
+		})
+	}
+
+	t.Run("unknown parts are not found", func(t *testing.T) {
+		_, err := ReadinessRead(&SvcConfig{URL: sc.URL, Service: "component:Revolution"})
+		assertError(t, err, CatalogNotFound)
+	})
+
+	t.Run("other kinds are not verified", func(t *testing.T) {
+		_, err := ReadinessRead(&SvcConfig{URL: sc.URL, Service: "group:code-owners-core"})
+		assertError(t, err, KindNotVerified)
+	})
+}
@@ -0,0 +1,89 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/tdabasinskas/go-backstage/v2/backstage"
+)
+
+var CatalogNotFound = errors.New("entity not found in the catalog")
+
+// Catalog is every read Verificat makes of the Service Catalog.
+// BackstageCatalog is the real one, tests point it at a fake Backstage.
+type Catalog interface {
+	ListSystems() ([]backstage.Entity, error)
+	ListEntities(filters ...string) ([]backstage.Entity, error)
+	GetSystem(name string) (*backstage.SystemEntityV1alpha1, error)
+	GetComponent(name string) (*backstage.ComponentEntityV1alpha1, error)
+	GetResource(name string) (*backstage.ResourceEntityV1alpha1, error)
+	GetAPI(name string) (*backstage.ApiEntityV1alpha1, error)
+}
+
+// BackstageCatalog reads the catalog from the Backstage API.
+type BackstageCatalog struct {
+	client *backstage.Client
+}
+
+// NewBackstageCatalog Constructor
+// /url/ is the Backstage API endpoint, entities are read from the default namespace.
+func NewBackstageCatalog(url string) (*BackstageCatalog, error) {
+	c, err := backstage.NewClient(url, "default", nil)
+	if err != nil {
+		return nil, fmt.Errorf("problem creating Backstage client, %v", err)
+	}
+	return &BackstageCatalog{client: c}, nil
+}
+
+// ListSystems returns every System definition ("kind=system").
+func (b *BackstageCatalog) ListSystems() ([]backstage.Entity, error) {
+	systems, resp, err := b.client.Catalog.Entities.List(context.Background(), &backstage.ListEntityOptions{Filters: []string{"kind=system"}})
+	return systems, catalogError(resp, err)
+}
+
+// ListEntities returns every entity matching any of the /filters/,
+// each a comma-separated list of conditions that must all hold, e.g.: "kind=component,spec.lifecycle=production".
+func (b *BackstageCatalog) ListEntities(filters ...string) ([]backstage.Entity, error) {
+	entities, resp, err := b.client.Catalog.Entities.List(context.Background(), &backstage.ListEntityOptions{Filters: filters})
+	return entities, catalogError(resp, err)
+}
+
+// GetSystem returns a System by name.
+func (b *BackstageCatalog) GetSystem(name string) (*backstage.SystemEntityV1alpha1, error) {
+	se, resp, err := b.client.Catalog.Systems.Get(context.Background(), name, "")
+	return se, catalogError(resp, err)
+}
+
+// GetComponent returns a Component by name.
+func (b *BackstageCatalog) GetComponent(name string) (*backstage.ComponentEntityV1alpha1, error) {
+	ce, resp, err := b.client.Catalog.Components.Get(context.Background(), name, "")
+	return ce, catalogError(resp, err)
+}
+
+// GetResource returns a Resource by name.
+func (b *BackstageCatalog) GetResource(name string) (*backstage.ResourceEntityV1alpha1, error) {
+	re, resp, err := b.client.Catalog.Resources.Get(context.Background(), name, "")
+	return re, catalogError(resp, err)
+}
+
+// GetAPI returns an API by name.
+func (b *BackstageCatalog) GetAPI(name string) (*backstage.ApiEntityV1alpha1, error) {
+	ae, resp, err := b.client.Catalog.APIs.Get(context.Background(), name, "")
+	return ae, catalogError(resp, err)
+}
+
+// catalogError adds the HTTP status to the client's error,
+// since the Backstage client decodes error bodies as if they were entities.
+func catalogError(resp *http.Response, err error) error {
+	switch {
+	case resp == nil:
+		return err
+	case resp.StatusCode == http.StatusNotFound:
+		return CatalogNotFound
+	case resp.StatusCode != http.StatusOK:
+		return fmt.Errorf("Backstage returned %d for %s", resp.StatusCode, resp.Request.URL.Path)
+	}
+	return err
+}
@@ -0,0 +1,92 @@
+package main
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"sort"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+// BackstageCatalog reports what Backstage answered, not what the client decoded from it
+func TestBackstageCatalog(t *testing.T) {
+	c, err := NewBackstageCatalog(newFakeBackstage(t, fakeBackstageFixtures))
+	assertError(t, err, nil)
+
+	t.Run("lists only Systems", func(t *testing.T) {
+		systems, err := c.ListSystems()
+		assertError(t, err, nil)
+		if len(systems) != 4 {
+			t.Errorf("got %d systems want 4", len(systems))
+		}
+		for _, s := range systems {
+			if s.Kind != "System" {
+				t.Errorf("got a %s %q in the System list", s.Kind, s.Metadata.Name)
+			}
+		}
+	})
+
+	t.Run("lists entities matching any filter", func(t *testing.T) {
+		entities, err := c.ListEntities("kind=resource", "kind=component,spec.lifecycle=experimental")
+		assertError(t, err, nil)
+
+		var names []string
+		for _, e := range entities {
+			names = append(names, e.Metadata.Name)
+		}
+		sort.Strings(names)
+		if diff := cmp.Diff(names, []string{"ad-server-bidder", "core-db"}); diff != "" {
+			t.Error(diff)
+		}
+	})
+
+	t.Run("gets Systems and Components by name", func(t *testing.T) {
+		system, err := c.GetSystem("core")
+		assertError(t, err, nil)
+		assertString(t, system.Metadata.Annotations[projectSlug], "GhostGroup/weedmaps")
+
+		component, err := c.GetComponent("core-braze")
+		assertError(t, err, nil)
+		assertString(t, component.Spec.System, "core")
+
+		resource, err := c.GetResource("core-db")
+		assertError(t, err, nil)
+		assertString(t, resource.Spec.Type, "database")
+
+		api, err := c.GetAPI("ad-server-api")
+		assertError(t, err, nil)
+		assertString(t, api.Spec.Owner, "code-owners-wasp")
+	})
+
+	t.Run("missing entities are CatalogNotFound", func(t *testing.T) {
+		_, err := c.GetSystem("core-braze")
+		assertError(t, err, CatalogNotFound)
+
+		_, err = c.GetComponent("core")
+		assertError(t, err, CatalogNotFound)
+
+		_, err = c.GetResource("core-braze")
+		assertError(t, err, CatalogNotFound)
+
+		_, err = c.GetAPI("core-db")
+		assertError(t, err, CatalogNotFound)
+	})
+
+	t.Run("other statuses are errors", func(t *testing.T) {
+		broken := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			http.Error(w, `{"error": {"name": "AuthenticationError"}}`, http.StatusUnauthorized)
+		}))
+		defer broken.Close()
+
+		c, err := NewBackstageCatalog(broken.URL)
+		assertError(t, err, nil)
+
+		if _, err := c.ListSystems(); err == nil {
+			t.Error("expected an error listing Systems")
+		}
+		if _, err := c.GetSystem("admin"); err == nil || err == CatalogNotFound {
+			t.Errorf("got %v want an error with the status", err)
+		}
+	})
+}
@@ -0,0 +1,64 @@
+package main
+
+import (
+	"errors"
+	"log/slog"
+)
+
+// codeownersCheck is the presence and syntax of the CODEOWNERS file.
+// GitHub silently skips broken lines, so they're reported here
+// rather than showing up as an Owner mismatch.
+type codeownersCheck struct{}
+
+func init() {
+	RegisterCheck(codeownersCheck{})
+}
+
+func (codeownersCheck) Info() CheckInfo {
+	return CheckInfo{
+		ID:          "codeowners",
+		Description: "GitHub CODEOWNERS is present and parses without errors",
+		Principles:  []Principle{Reliability, Documentation},
+	}
+}
+
+// TestItem reports every line GitHub would skip as its own Finding.
+// A missing file is a Finding at each place GitHub looks, and costs the point the owner Check can't verify.
+// Without a repository there's nothing to read, the "repository" Check reports why.
+// GitHub failing to answer costs nothing, the run is tried again.
+func (codeownersCheck) TestItem(s *SvcTestDB) *TestReturn {
+	co, path, err := s.readCodeowners()
+	if errors.Is(err, errRepoUnresolved) {
+		return &TestReturn{Owner: s.Owner, Reality: repoUnresolved, Works: true, Score: s.Score}
+	}
+	if s.unavailable(err) {
+		return &TestReturn{Owner: s.Owner, Reality: err.Error(), Source: path, Score: s.Score}
+	}
+	if errors.Is(err, CodeownersMissing) {
+		s.Score--
+		slog.Warn("Missing CODEOWNERS", slog.String("Repo", s.Repo), slog.Int("Score", s.Score))
+		var findings []Finding
+		for _, p := range codeownersPaths {
+			findings = append(findings, Finding{Path: p, Message: "not found"})
+		}
+		return &TestReturn{Present: false, Owner: s.Owner, Reality: err.Error(), Works: false, Score: s.Score, Findings: findings}
+	}
+	if err != nil {
+		s.Score--
+		slog.Warn("Unreadable CODEOWNERS", slog.String("Repo", s.Repo), slog.Any("Error", err), slog.Int("Score", s.Score))
+		return &TestReturn{Present: false, Owner: s.Owner, Reality: err.Error(), Source: path, Works: false, Score: s.Score,
+			Findings: []Finding{{Path: path, Message: err.Error()}}}
+	}
+
+	var findings []Finding
+	for _, e := range co.Errors {
+		findings = append(findings, Finding{Path: path, Line: e.Line, Message: e.Reason})
+	}
+	if len(findings) > 0 {
+		s.Score--
+		slog.Warn("CODEOWNERS Errors", slog.String("Repo", s.Repo), slog.Int("Errors", len(findings)), slog.Int("Score", s.Score))
+		return &TestReturn{Present: true, Owner: s.Owner, Reality: path, Source: path, Works: false, Score: s.Score, Findings: findings}
+	}
+
+	return &TestReturn{Present: true, Owner: s.Owner, Reality: path, Source: path, Works: true, Score: s.Score}
+}
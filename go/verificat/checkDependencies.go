@@ -0,0 +1,73 @@
+package main
+
+import (
+	"fmt"
+	"log/slog"
+)
+
+// dependencyCheck looks at what a production service relies on in the catalog.
+type dependencyCheck struct{}
+
+func init() {
+	RegisterCheck(dependencyCheck{})
+}
+
+func (dependencyCheck) Info() CheckInfo {
+	return CheckInfo{
+		ID:          "dependencies",
+		Description: "A production service only depends on entities in the catalog that are not experimental",
+		Principles:  []Principle{Stability, FaultTolerance},
+		Required:    false,
+	}
+}
+
+// TestItem is returning a test result to ReadinessDisplay
+// A production service is only as stable as what it depends on,
+// so depending on something experimental, or something nobody put in the catalog, is a finding.
+// Services that aren't in production yet may depend on whatever they like.
+func (dependencyCheck) TestItem(s *SvcTestDB) *TestReturn {
+	g, err := s.readDependencies()
+	if err == errCatalogUnavailable {
+		// Nothing to read the graph from, e.g.: a run without Backstage
+		return &TestReturn{Owner: s.Owner, Reality: "dependencies not checked, no catalog", Works: true, Score: s.Score}
+	}
+	if err != nil {
+		s.Score--
+		slog.Info("New Adjustment", slog.Int("Score", s.Score))
+		return &TestReturn{Owner: s.Owner, Reality: err.Error(), Score: s.Score, Findings: []Finding{{Message: err.Error()}}}
+	}
+
+	deps := g.DependenciesOf(s.Service)
+	lifecycle := g.Lifecycle(s.Service)
+	reality := fmt.Sprintf("%d dependencies, lifecycle %q", len(deps), lifecycle)
+	if lifecycle != LifecycleProduction {
+		slog.Info("Dependencies Not Checked", slog.String("Service", s.Service), slog.String("Lifecycle", lifecycle))
+		return &TestReturn{Present: true, Owner: s.Owner, Reality: reality, Works: true, Score: s.Score}
+	}
+
+	var findings []Finding
+	for _, dep := range deps {
+		declarer := s.Service
+		if dep.Via != "" {
+			declarer = dep.Via
+		}
+		switch {
+		case !g.InCatalog(dep.Name):
+			findings = append(findings, Finding{Path: dep.Field, Message: fmt.Sprintf("%s depends on %s, which is not in the catalog", declarer, dep.Name)})
+		case g.Lifecycle(dep.Name) == LifecycleExperimental:
+			findings = append(findings, Finding{Path: dep.Field, Message: fmt.Sprintf("%s depends on %s, which is experimental", declarer, dep.Name)})
+		}
+	}
+
+	works := len(findings) == 0
+	if works {
+		slog.Info("Dependencies Ready", slog.String("Service", s.Service), slog.Int("Dependencies", len(deps)), slog.Int("Score", s.Score))
+	} else {
+		slog.Warn("Unready Dependencies", slog.String("Service", s.Service), slog.Int("Findings", len(findings)))
+		s.Score--
+		slog.Info("New Adjustment", slog.Int("Score", s.Score))
+	}
+
+	// This will be included in the API return value
+	return &TestReturn{Present: true, Owner: s.Owner, Reality: reality, Works: works, Score: s.Score, Findings: findings}
+}
@@ -0,0 +1,176 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"log/slog"
+	"slices"
+	"strings"
+)
+
+// Where the Dockerfile is looked for in the Service's repository, in order, DOCKERFILE_PATHS replaces them.
+// The labels the image needs, DOCKERFILE_LABELS replaces them.
+var (
+	dockerfilePaths  = []string{"Dockerfile", "docker/Dockerfile", "build/Dockerfile"}
+	dockerfileLabels = []string{"org.opencontainers.image.source"}
+)
+
+// secretWords are the parts of an ENV name that say it holds a secret, e.g.: GH_TOKEN.
+var secretWords = []string{"SECRET", "TOKEN", "PASSWORD", "PASSWD", "API_KEY", "APIKEY", "PRIVATE_KEY", "ACCESS_KEY", "CREDENTIAL"}
+
+// dockerfileCheck is the Verification of how the Service's image is built:
+// pinned base images, a multi-stage build, a user other than root, a HEALTHCHECK, labels, and no secrets baked in.
+type dockerfileCheck struct{}
+
+func init() {
+	RegisterCheck(dockerfileCheck{})
+}
+
+func (dockerfileCheck) Info() CheckInfo {
+	return CheckInfo{
+		ID:          "dockerfile",
+		Description: "GitHub Dockerfile pins its base images, builds in stages, runs as a user, and keeps secrets out",
+		Principles:  []Principle{Stability, Reliability, CatastrophePreparedness},
+	}
+}
+
+// TestItem reports every problem in the Dockerfile as its own Finding, at the cost of a single point.
+// A repository without a Dockerfile isn't judged, e.g.: a library or a Helm chart of someone else's image.
+// GitHub failing to answer costs nothing, the run is tried again.
+func (dockerfileCheck) TestItem(s *SvcTestDB) *TestReturn {
+	found, body, err := s.probeRepo(envList("DOCKERFILE_PATHS", dockerfilePaths))
+	switch {
+	case errors.Is(err, errRepoUnresolved):
+		return &TestReturn{Owner: s.Owner, Reality: repoUnresolved, Works: true, Score: s.Score}
+	case errors.Is(err, GitHubNotFound):
+		return &TestReturn{Owner: s.Owner, Reality: err.Error(), Works: true, Score: s.Score}
+	case s.unavailable(err):
+		return &TestReturn{Owner: s.Owner, Reality: err.Error(), Score: s.Score}
+	case err != nil:
+		s.Score--
+		return &TestReturn{Owner: s.Owner, Reality: err.Error(), Score: s.Score, Findings: []Finding{{Message: err.Error()}}}
+	}
+
+	d, err := ParseDockerfile(strings.NewReader(body))
+	if err == nil && len(d.Stages) == 0 {
+		err = fmt.Errorf("%s has no FROM", found)
+	}
+	if err != nil {
+		s.Score--
+		slog.Warn("Unreadable Dockerfile", slog.String("Repo", s.Repo), slog.String("Path", found), slog.Any("Error", err), slog.Int("Score", s.Score))
+		return &TestReturn{Present: true, Owner: s.Owner, Reality: err.Error(), Source: found, Score: s.Score, Findings: []Finding{{Path: found, Message: err.Error()}}}
+	}
+
+	findings := inspectDockerfile(found, d)
+	reality := "FROM " + d.Final().Image
+
+	if len(findings) > 0 {
+		s.Score--
+		slog.Warn("Dockerfile Findings", slog.String("Repo", s.Repo), slog.String("Path", found), slog.Int("Findings", len(findings)), slog.Int("Score", s.Score))
+		return &TestReturn{Present: true, Owner: s.Owner, Reality: reality, Source: found, Score: s.Score, Findings: findings}
+	}
+
+	slog.Info("Dockerfile Hygienic", slog.String("Repo", s.Repo), slog.String("Path", found), slog.Int("Score", s.Score))
+	return &TestReturn{Present: true, Owner: s.Owner, Reality: reality, Source: found, Works: true, Score: s.Score}
+}
+
+// inspectDockerfile finds the problems in the Dockerfile at /path/, each at the line of the instruction it's about.
+func inspectDockerfile(path string, d *Dockerfile) []Finding {
+	var findings []Finding
+	add := func(line int, format string, a ...any) {
+		findings = append(findings, Finding{Path: path, Line: line, Message: fmt.Sprintf(format, a...)})
+	}
+	final := d.Final()
+
+	// Every base image, skipping earlier stages and images chosen by an ARG
+	var stages []string
+	for _, st := range d.Stages {
+		if problem := inspectBaseImage(st.Image, stages); problem != "" {
+			add(st.From.Line, "%s", problem)
+		}
+		if st.Name != "" {
+			stages = append(stages, st.Name)
+		}
+	}
+
+	if len(d.Stages) < 2 {
+		add(final.From.Line, "single build stage, the build tools ship in the image")
+	}
+
+	switch user := final.Last("USER"); {
+	case user == nil:
+		add(final.From.Line, "no USER, the container runs as root")
+	case isRootUser(user.Args):
+		add(user.Line, "USER %s runs the container as root", user.Args)
+	}
+
+	if hc := final.Last("HEALTHCHECK"); hc == nil || strings.EqualFold(hc.Args, "NONE") {
+		add(final.From.Line, "no HEALTHCHECK")
+	}
+
+	labels := make(map[string]bool)
+	for _, in := range final.Instructions {
+		if in.Cmd == "LABEL" {
+			for _, kv := range keyValues(in.Args) {
+				labels[kv.Key] = true
+			}
+		}
+	}
+	for _, want := range envList("DOCKERFILE_LABELS", dockerfileLabels) {
+		if !labels[want] {
+			add(final.From.Line, "no LABEL %s", want)
+		}
+	}
+
+	for _, st := range d.Stages {
+		for _, in := range st.Instructions {
+			if in.Cmd != "ENV" {
+				continue
+			}
+			for _, kv := range keyValues(in.Args) {
+				if kv.Value != "" && isSecretName(kv.Key) {
+					add(in.Line, "ENV %s puts a secret in the image, pass it at runtime or as a build secret", kv.Key)
+				}
+			}
+		}
+	}
+	return findings
+}
+
+// inspectBaseImage says what's wrong with a base image, nothing for one that's pinned to a digest.
+// A tag can be moved, a digest can't.
+func inspectBaseImage(image string, stages []string) string {
+	switch {
+	case image == "scratch", slices.Contains(stages, image), strings.HasPrefix(image, "$"):
+		return ""
+	case strings.Contains(image, "@sha256:"):
+		return ""
+	}
+
+	// The tag follows the last colon after the last slash, which keeps a registry's port out of it
+	tag := ""
+	if i := strings.LastIndex(image, ":"); i > strings.LastIndex(image, "/") {
+		tag = image[i+1:]
+	}
+	if tag == "" || tag == "latest" {
+		return fmt.Sprintf("FROM %s uses the latest tag", image)
+	}
+	return fmt.Sprintf("FROM %s isn't pinned to a digest", image)
+}
+
+// isRootUser is true for root by name or ID, with or without a group.
+func isRootUser(user string) bool {
+	name, _, _ := strings.Cut(strings.TrimSpace(user), ":")
+	return name == "root" || name == "0"
+}
+
+// isSecretName is true for an ENV name that looks like it holds a secret.
+func isSecretName(name string) bool {
+	name = strings.ToUpper(name)
+	for _, w := range secretWords {
+		if strings.Contains(name, w) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,113 @@
+package main
+
+import (
+	"net/http"
+	"os"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+// Every problem is its own Finding, at the line of its instruction
+func TestDockerfileCheck(t *testing.T) {
+	gh, _ := newFakeGitHub(t, fakeGitHubFixtures)
+
+	dockerfileTests := []struct {
+		Repo     string
+		Works    bool
+		Source   string
+		Findings []Finding
+	}{
+		{"GhostGroup/weedmaps", true, "Dockerfile", nil},
+		{"GhostGroup/admin", false, "Dockerfile", []Finding{
+			{Path: "Dockerfile", Line: 1, Message: "FROM node:latest uses the latest tag"},
+			{Path: "Dockerfile", Line: 1, Message: "single build stage, the build tools ship in the image"},
+			{Path: "Dockerfile", Line: 9, Message: "USER root runs the container as root"},
+			{Path: "Dockerfile", Line: 1, Message: "no HEALTHCHECK"},
+			{Path: "Dockerfile", Line: 1, Message: "no LABEL org.opencontainers.image.source"},
+			{Path: "Dockerfile", Line: 4, Message: "ENV SESSION_SECRET puts a secret in the image, pass it at runtime or as a build secret"},
+		}},
+		{"GhostGroup/ad-server", true, "", nil}, // No Dockerfile to judge
+		{"", true, "", nil},
+	}
+
+	for _, tt := range dockerfileTests {
+		t.Run(tt.Repo, func(t *testing.T) {
+			s := &SvcTestDB{Repo: tt.Repo, GitHub: gh, Score: 100}
+			got := dockerfileCheck{}.TestItem(s)
+
+			if got.Works != tt.Works || got.Source != tt.Source {
+				t.Errorf("got works %t from %q want works %t from %q", got.Works, got.Source, tt.Works, tt.Source)
+			}
+			if diff := cmp.Diff(got.Findings, tt.Findings); diff != "" {
+				t.Error(diff)
+			}
+			assertCharged(t, s, tt.Works)
+		})
+	}
+
+	t.Run("GitHub failing to answer costs nothing", func(t *testing.T) {
+		down, _, _ := newTestGitHubClient(t, answer(http.StatusServiceUnavailable, ""))
+		s := &SvcTestDB{Repo: "GhostGroup/admin", GitHub: down, Score: 100}
+		got := dockerfileCheck{}.TestItem(s)
+		if got.Works || len(got.Findings) != 0 {
+			t.Errorf("got %+v want an unjudged failure", got)
+		}
+		assertScore(t, s.Score, 100)
+		assertError(t, s.Outage(), errGitHubTransient)
+	})
+
+	t.Run("labels and paths come from the environment", func(t *testing.T) {
+		t.Setenv("DOCKERFILE_PATHS", "build/Dockerfile,Dockerfile")
+		t.Setenv("DOCKERFILE_LABELS", "org.opencontainers.image.source,org.opencontainers.image.version")
+		s := &SvcTestDB{Repo: "GhostGroup/weedmaps", GitHub: gh, Score: 100}
+		got := dockerfileCheck{}.TestItem(s)
+		if diff := cmp.Diff(got.Findings, []Finding{{Path: "Dockerfile", Line: 11, Message: "no LABEL org.opencontainers.image.version"}}); diff != "" {
+			t.Error(diff)
+		}
+	})
+}
+
+// Verificat's own image is built the way this Check flags
+func TestInspectOwnDockerfile(t *testing.T) {
+	f, err := os.Open("Dockerfile")
+	assertNoError(t, err)
+	defer f.Close()
+
+	d, err := ParseDockerfile(f)
+	assertNoError(t, err)
+
+	var got []string
+	for _, f := range inspectDockerfile("Dockerfile", d) {
+		got = append(got, f.Message)
+	}
+	want := []string{
+		"FROM ghcr.io/ghostgroup/docker-hub/golang:alpine3.20 isn't pinned to a digest",
+		"single build stage, the build tools ship in the image",
+		"no USER, the container runs as root",
+		"no HEALTHCHECK",
+	}
+	if diff := cmp.Diff(got, want); diff != "" {
+		t.Error(diff)
+	}
+}
+
+// A base image is judged by its digest, then its tag
+func TestInspectBaseImage(t *testing.T) {
+	imageTests := []struct {
+		Image string
+		Want  string
+	}{
+		{"golang", "FROM golang uses the latest tag"},
+		{"golang:latest", "FROM golang:latest uses the latest tag"},
+		{"localhost:5000/golang", "FROM localhost:5000/golang uses the latest tag"},
+		{"localhost:5000/golang:1.22", "FROM localhost:5000/golang:1.22 isn't pinned to a digest"},
+		{"golang:1.22@sha256:0d2ab2a3c6e4b4e3a6f1d1c3b8a9e7f5c4d3b2a1908f7e6d5c4b3a2918f7e6d5", ""},
+		{"scratch", ""},
+		{"build", ""},
+		{"${BASE_IMAGE}", ""},
+	}
+	for _, tt := range imageTests {
+		assertString(t, inspectBaseImage(tt.Image, []string{"build"}), tt.Want)
+	}
+}
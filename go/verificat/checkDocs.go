@@ -0,0 +1,202 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"path"
+	"strings"
+)
+
+// readmeMinWords is how many words, outside of its headings, make a README more than a title.
+const readmeMinWords = 30
+
+// Where the documentation is looked for in the Service's repository, in order.
+// The runbook and ADR paths can be replaced with RUNBOOK_PATHS and ADR_PATHS.
+var (
+	readmePaths  = []string{"README.md", "README", "README.rst", "docs/README.md"}
+	runbookPaths = []string{"docs/runbook.md", "RUNBOOK.md", "runbook.md", "docs/RUNBOOK.md", "docs/runbooks/README.md"}
+	adrPaths     = []string{"docs/adr", "docs/adrs", "docs/decisions", "docs/architecture", "adr"}
+)
+
+// docCheck is the Verification that a piece of documentation exists in the Service's repository.
+// It probes each of its paths and judges the first one found,
+// so the README, runbook, ADRs and TechDocs differ only in where they look and what counts.
+type docCheck struct {
+	CheckInfo
+	paths func(s *SvcTestDB) ([]string, string) // Where to look, or why there's nothing to look for
+	judge func(found, body string) error        // Whether what was found counts, nil takes anything
+}
+
+// docChecks are the Documentation Principle's Checks.
+var docChecks = []docCheck{
+	{
+		CheckInfo: CheckInfo{
+			ID:          "readme",
+			Description: "GitHub README says more than the service's name",
+			Principles:  []Principle{Documentation},
+		},
+		paths: func(*SvcTestDB) ([]string, string) { return readmePaths, "" },
+		judge: judgeReadme,
+	},
+	{
+		CheckInfo: CheckInfo{
+			ID:          "runbook",
+			Description: "GitHub runbook is present for whoever is on call",
+			Principles:  []Principle{Documentation, CatastrophePreparedness},
+		},
+		paths: func(*SvcTestDB) ([]string, string) {
+			return envList("RUNBOOK_PATHS", runbookPaths), ""
+		},
+	},
+	{
+		CheckInfo: CheckInfo{
+			ID:          "adr",
+			Description: "GitHub architecture decision records are kept in a directory",
+			Principles:  []Principle{Documentation},
+		},
+		paths: func(*SvcTestDB) ([]string, string) {
+			return envList("ADR_PATHS", adrPaths), ""
+		},
+		judge: judgeADRs,
+	},
+	{
+		CheckInfo: CheckInfo{
+			ID:          "techdocs",
+			Description: "GitHub mkdocs.yml is present when Backstage builds TechDocs from the repository",
+			Principles:  []Principle{Documentation},
+		},
+		paths: techdocsPaths,
+	},
+}
+
+func init() {
+	for _, c := range docChecks {
+		RegisterCheck(c)
+	}
+}
+
+// TestItem reports the file it found in Source, or every path it probed as a Finding.
+// Missing documentation costs a point.
+// Without a repository there's nothing to probe, the "repository" Check reports why.
+// GitHub failing to answer costs nothing, the run is tried again.
+func (c docCheck) TestItem(s *SvcTestDB) *TestReturn {
+	paths, reason := c.paths(s)
+	if len(paths) == 0 {
+		// e.g.: TechDocs aren't built from this repository
+		return &TestReturn{Owner: s.Owner, Reality: reason, Works: true, Score: s.Score}
+	}
+
+	found, body, err := s.probeRepo(paths)
+	switch {
+	case errors.Is(err, errRepoUnresolved):
+		return &TestReturn{Owner: s.Owner, Reality: repoUnresolved, Works: true, Score: s.Score}
+	case s.unavailable(err):
+		return &TestReturn{Owner: s.Owner, Reality: err.Error(), Score: s.Score}
+	case err == nil && c.judge != nil:
+		err = c.judge(found, body)
+	}
+	if err == nil {
+		slog.Info("Documentation Found", slog.String("Check", c.ID), slog.String("Repo", s.Repo), slog.String("Path", found), slog.Int("Score", s.Score))
+		return &TestReturn{Present: true, Owner: s.Owner, Reality: found, Source: found, Works: true, Score: s.Score}
+	}
+
+	s.Score--
+	slog.Warn("Documentation Missing", slog.String("Check", c.ID), slog.String("Repo", s.Repo), slog.Any("Error", err), slog.Int("Score", s.Score))
+
+	var findings []Finding
+	switch {
+	case found != "":
+		// Found, but it doesn't count
+		findings = []Finding{{Path: found, Message: err.Error()}}
+	case errors.Is(err, GitHubNotFound):
+		for _, p := range paths {
+			findings = append(findings, Finding{Path: p, Message: "not found"})
+		}
+	default:
+		// e.g.: the token can't read the repository
+		findings = []Finding{{Message: err.Error()}}
+	}
+	return &TestReturn{Present: found != "", Owner: s.Owner, Reality: err.Error(), Source: found, Score: s.Score, Findings: findings}
+}
+
+// probeRepo fetches each of /paths/ from the Service's repository and returns the first that exists.
+// A directory's body is GitHub's JSON listing of it.
+// Nothing found is GitHubNotFound, naming every path probed.
+func (s *SvcTestDB) probeRepo(paths []string) (string, string, error) {
+	if s.Repo == "" {
+		return "", "", errRepoUnresolved
+	}
+
+	gh := s.github()
+	for _, p := range paths {
+		body, err := gh.Get(urlCat(gh.BaseURL, ghPreURI, s.Repo, ghContentsPATH, p))
+		if errors.Is(err, GitHubNotFound) {
+			continue
+		}
+		if err != nil {
+			slog.Error("Cannot Fetch", slog.String("Path", p), slog.Any("Error", err))
+			return "", "", err
+		}
+		return p, body, nil
+	}
+	return "", "", fmt.Errorf("%w, probed %s", GitHubNotFound, strings.Join(paths, ", "))
+}
+
+// judgeReadme wants some prose, not just a title and a badge.
+func judgeReadme(found, body string) error {
+	words := 0
+	for _, line := range strings.Split(body, "\n") {
+		line = strings.TrimSpace(line)
+		if strings.HasPrefix(line, "#") || strings.HasPrefix(line, "[![") || strings.HasPrefix(line, "=") {
+			continue
+		}
+		words += len(strings.Fields(line))
+	}
+	if words < readmeMinWords {
+		return fmt.Errorf("%s has %d words, at least %d are expected", found, words, readmeMinWords)
+	}
+	return nil
+}
+
+// ghContent is an entry in GitHub's listing of a directory.
+type ghContent struct {
+	Name string `json:"name"`
+	Path string `json:"path"`
+	Type string `json:"type"` // file or dir
+}
+
+// judgeADRs wants a directory with at least one record in it.
+func judgeADRs(found, body string) error {
+	var listing []ghContent
+	if err := json.Unmarshal([]byte(body), &listing); err != nil {
+		return fmt.Errorf("%s is a file, not a directory", found)
+	}
+	for _, entry := range listing {
+		if entry.Type == "file" {
+			return nil
+		}
+	}
+	return fmt.Errorf("%s has no records in it", found)
+}
+
+// techdocsPaths are where mkdocs.yml should be when the entity's backstage.io/techdocs-ref
+// points into its own repository, e.g.: dir:. or dir:./docs-site.
+func techdocsPaths(s *SvcTestDB) ([]string, string) {
+	if s.Metadata == nil {
+		return nil, "not read from the catalog"
+	}
+	ref := strings.TrimSpace(s.Metadata.Annotations[techdocsRef])
+	if ref == "" {
+		return nil, "no " + techdocsRef + " annotation"
+	}
+	dir, ok := strings.CutPrefix(ref, "dir:")
+	if !ok {
+		// e.g.: url:, which could be any repository
+		return nil, "TechDocs are built from " + ref
+	}
+
+	dir = path.Clean(strings.TrimSpace(dir))
+	return []string{path.Join(dir, "mkdocs.yml"), path.Join(dir, "mkdocs.yaml")}, ""
+}
@@ -0,0 +1,123 @@
+package main
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/tdabasinskas/go-backstage/v2/backstage"
+)
+
+// Each Check reports the file it found, or the paths it probed
+func TestDocChecks(t *testing.T) {
+	gh, _ := newFakeGitHub(t, fakeGitHubFixtures)
+	techdocs := &backstage.EntityMeta{Annotations: map[string]string{techdocsRef: "dir:."}}
+
+	docTests := []struct {
+		Check    string
+		Repo     string
+		Works    bool
+		Source   string
+		Findings []Finding
+	}{
+		{"readme", "GhostGroup/weedmaps", true, "README.md", nil},
+		{"readme", "GhostGroup/admin", false, "README.md", []Finding{{Path: "README.md", Message: "README.md has 3 words, at least 30 are expected"}}},
+		{"runbook", "GhostGroup/weedmaps", true, "docs/runbook.md", nil},
+		{"runbook", "GhostGroup/admin", true, "RUNBOOK.md", nil},
+		{"adr", "GhostGroup/weedmaps", true, "docs/adr", nil},
+		{"adr", "GhostGroup/ad-server", false, "", []Finding{
+			{Path: "docs/adr", Message: "not found"},
+			{Path: "docs/adrs", Message: "not found"},
+			{Path: "docs/decisions", Message: "not found"},
+			{Path: "docs/architecture", Message: "not found"},
+			{Path: "adr", Message: "not found"},
+		}},
+		{"techdocs", "GhostGroup/weedmaps", true, "mkdocs.yml", nil},
+		{"techdocs", "GhostGroup/admin", false, "", []Finding{
+			{Path: "mkdocs.yml", Message: "not found"},
+			{Path: "mkdocs.yaml", Message: "not found"},
+		}},
+		{"runbook", "", true, "", nil}, // The repository Check reports it
+	}
+
+	for _, tt := range docTests {
+		t.Run(tt.Check+" in "+tt.Repo, func(t *testing.T) {
+			s := &SvcTestDB{Repo: tt.Repo, GitHub: gh, Metadata: techdocs, Score: 100}
+			c, ok := LookupCheck(tt.Check)
+			if !ok {
+				t.Fatalf("no Check %q", tt.Check)
+			}
+			got := c.TestItem(s)
+
+			if got.Works != tt.Works || got.Source != tt.Source {
+				t.Errorf("got works %t from %q want works %t from %q", got.Works, got.Source, tt.Works, tt.Source)
+			}
+			if diff := cmp.Diff(got.Findings, tt.Findings); diff != "" {
+				t.Error(diff)
+			}
+			assertCharged(t, s, tt.Works)
+		})
+	}
+
+	t.Run("only a missing file is reported at every path", func(t *testing.T) {
+		readme, _ := LookupCheck("readme")
+
+		// GitHub being down says nothing about the documentation
+		down, _, _ := newTestGitHubClient(t, answer(http.StatusServiceUnavailable, ""))
+		s := &SvcTestDB{Repo: "GhostGroup/admin", GitHub: down, Score: 100}
+		got := readme.TestItem(s)
+		if got.Works || len(got.Findings) != 0 || s.Score != 100 {
+			t.Errorf("got %+v want a failure that costs nothing", got)
+		}
+		assertError(t, s.Outage(), errGitHubTransient)
+
+		// Anything else is one Finding
+		forbidden, _, _ := newTestGitHubClient(t, answer(http.StatusForbidden, ""))
+		s = &SvcTestDB{Repo: "GhostGroup/admin", GitHub: forbidden, Score: 100}
+		got = readme.TestItem(s)
+		if diff := cmp.Diff(got.Findings, []Finding{{Message: got.Reality}}); diff != "" {
+			t.Error(diff)
+		}
+		assertScore(t, s.Score, 99)
+		assertError(t, s.Outage(), nil)
+	})
+
+	t.Run("paths come from the environment", func(t *testing.T) {
+		t.Setenv("RUNBOOK_PATHS", "ops/runbook.md, README.md")
+		t.Setenv("ADR_PATHS", "README.md")
+		s := &SvcTestDB{Repo: "GhostGroup/ad-server", GitHub: gh, Score: 100}
+
+		runbook, _ := LookupCheck("runbook")
+		got := runbook.TestItem(s)
+		assertString(t, got.Source, "README.md")
+
+		// A file where the records should be doesn't count
+		adr, _ := LookupCheck("adr")
+		got = adr.TestItem(s)
+		assertString(t, got.Reality, "README.md is a file, not a directory")
+	})
+
+	t.Run("mkdocs.yml is looked for where techdocs-ref points", func(t *testing.T) {
+		s := &SvcTestDB{Repo: "GhostGroup/weedmaps", GitHub: gh, Score: 100,
+			Metadata: &backstage.EntityMeta{Annotations: map[string]string{techdocsRef: "dir:./site"}}}
+		techdocs, _ := LookupCheck("techdocs")
+		got := techdocs.TestItem(s)
+		if got.Works || got.Findings[0].Path != "site/mkdocs.yml" {
+			t.Errorf("got %+v want site/mkdocs.yml missing", got)
+		}
+	})
+
+	t.Run("TechDocs built elsewhere aren't looked for", func(t *testing.T) {
+		techdocs, _ := LookupCheck("techdocs")
+		for _, meta := range []*backstage.EntityMeta{
+			nil,
+			{},
+			{Annotations: map[string]string{techdocsRef: "url:https://github.com/GhostGroup/docs/tree/main/weedmaps"}},
+		} {
+			s := &SvcTestDB{Repo: "GhostGroup/admin", GitHub: gh, Metadata: meta, Score: 100}
+			if got := techdocs.TestItem(s); !got.Works || s.Score != 100 {
+				t.Errorf("got %+v for %+v want a pass", got, meta)
+			}
+		}
+	})
+}
@@ -0,0 +1,238 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"strconv"
+	"strings"
+)
+
+// Where the governance files are looked for in the Service's repository, in order.
+// GitHub reads SECURITY.md from the root, .github/ or docs/, Renovate reads any of its config files.
+var (
+	securityPaths   = []string{"SECURITY.md", ".github/SECURITY.md", "docs/SECURITY.md"}
+	licensePaths    = []string{"LICENSE", "LICENSE.md", "LICENSE.txt", "COPYING"}
+	dependencyPaths = []string{
+		".github/dependabot.yml", ".github/dependabot.yaml",
+		"renovate.json", "renovate.json5", ".github/renovate.json", ".github/renovate.json5", ".renovaterc", ".renovaterc.json",
+	}
+)
+
+// governanceChecks are the files a repository needs besides its documentation.
+// They're docChecks, probing their paths the same way.
+var governanceChecks = []docCheck{
+	{
+		CheckInfo: CheckInfo{
+			ID:          "security-policy",
+			Description: "GitHub SECURITY.md says how to report a vulnerability",
+			Principles:  []Principle{CatastrophePreparedness, Documentation},
+		},
+		paths: func(*SvcTestDB) ([]string, string) { return securityPaths, "" },
+	},
+	{
+		CheckInfo: CheckInfo{
+			ID:          "license",
+			Description: "GitHub LICENSE is present",
+			Principles:  []Principle{Documentation},
+		},
+		paths: func(*SvcTestDB) ([]string, string) { return licensePaths, "" },
+	},
+	{
+		CheckInfo: CheckInfo{
+			ID:          "dependency-updates",
+			Description: "GitHub Dependabot or Renovate keeps dependencies up to date",
+			Principles:  []Principle{Stability, Reliability},
+		},
+		paths: func(*SvcTestDB) ([]string, string) { return dependencyPaths, "" },
+	},
+}
+
+func init() {
+	RegisterCheck(branchProtectionCheck{})
+	for _, c := range governanceChecks {
+		RegisterCheck(c)
+	}
+}
+
+// branchProtectionCheck is the Verification that changes reach the default branch by review:
+// approving reviews and passing status checks are required, and force pushes are blocked.
+type branchProtectionCheck struct{}
+
+func (branchProtectionCheck) Info() CheckInfo {
+	return CheckInfo{
+		ID:          "branch-protection",
+		Description: "GitHub default branch requires reviews and status checks, and blocks force pushes",
+		Principles:  []Principle{Stability, Reliability},
+	}
+}
+
+// ghRepository is the part of GitHub's repository answer Verificat reads.
+type ghRepository struct {
+	DefaultBranch string `json:"default_branch"`
+}
+
+// ghBranch is the part of GitHub's branch answer Verificat reads.
+// It says whether the branch is protected with only read access to the repository.
+type ghBranch struct {
+	Protected bool `json:"protected"`
+}
+
+// ghEnabled is how GitHub answers a branch protection setting that's either on or off.
+type ghEnabled struct {
+	Enabled bool `json:"enabled"`
+}
+
+// ghBranchProtection is the part of GitHub's branch protection answer Verificat reads.
+// A setting that's off is left out of the answer, hence the pointers.
+type ghBranchProtection struct {
+	RequiredPullRequestReviews *struct {
+		RequiredApprovingReviewCount int  `json:"required_approving_review_count"`
+		RequireCodeOwnerReviews      bool `json:"require_code_owner_reviews"`
+	} `json:"required_pull_request_reviews"`
+	RequiredStatusChecks *struct {
+		Strict   bool     `json:"strict"`
+		Contexts []string `json:"contexts"`
+	} `json:"required_status_checks"`
+	AllowForcePushes ghEnabled `json:"allow_force_pushes"`
+}
+
+// minReviews reads BRANCH_MIN_REVIEWS, how many approving reviews the default branch needs.
+// Unset or not a number, it's 1.
+func minReviews() int {
+	n, err := strconv.Atoi(fillEnvVar("BRANCH_MIN_REVIEWS"))
+	if err != nil || n < 1 {
+		return 1
+	}
+	return n
+}
+
+// TestItem reports the protection observed as Reality, and each setting short of it as a Finding
+// at the setting's name in GitHub's API, at the cost of a single point.
+// Without a repository there's no branch to read, the "repository" Check reports why.
+// GitHub failing to answer costs nothing, the run is tried again.
+func (branchProtectionCheck) TestItem(s *SvcTestDB) *TestReturn {
+	if s.Repo == "" {
+		return &TestReturn{Owner: s.Owner, Reality: repoUnresolved, Works: true, Score: s.Score}
+	}
+
+	branch, protection, err := s.readBranchProtection()
+	if errors.Is(err, errBranchUnprotected) {
+		s.Score--
+		message := fmt.Sprintf("%s isn't protected", branch)
+		slog.Warn("Branch Unprotected", slog.String("Repo", s.Repo), slog.String("Branch", branch), slog.Int("Score", s.Score))
+		return &TestReturn{Owner: s.Owner, Reality: message, Source: branch, Score: s.Score, Findings: []Finding{{Path: "branches/" + branch, Message: message}}}
+	}
+	if s.unavailable(err) {
+		return &TestReturn{Owner: s.Owner, Reality: err.Error(), Source: branch, Score: s.Score}
+	}
+	if errors.Is(err, errProtectionHidden) {
+		// The token can't see the settings, which says nothing about the Service
+		reality := fmt.Sprintf("%s is protected, its settings can't be read", branch)
+		slog.Warn("Branch Protection Hidden", slog.String("Repo", s.Repo), slog.String("Branch", branch), slog.Any("Error", err), slog.Int("Score", s.Score))
+		return &TestReturn{Present: true, Owner: s.Owner, Reality: reality, Source: branch, Works: true, Score: s.Score}
+	}
+	if err != nil {
+		s.Score--
+		slog.Warn("Unreadable Branch Protection", slog.String("Repo", s.Repo), slog.Any("Error", err), slog.Int("Score", s.Score))
+		return &TestReturn{Owner: s.Owner, Reality: err.Error(), Source: branch, Score: s.Score, Findings: []Finding{{Message: err.Error()}}}
+	}
+
+	var observed []string
+	var findings []Finding
+
+	reviews, codeOwners := 0, false
+	if r := protection.RequiredPullRequestReviews; r != nil {
+		reviews, codeOwners = r.RequiredApprovingReviewCount, r.RequireCodeOwnerReviews
+	}
+	observed = append(observed, fmt.Sprintf("%d approving reviews", reviews))
+	if codeOwners {
+		observed = append(observed, "code owner review")
+	}
+	if want := minReviews(); reviews < want {
+		findings = append(findings, Finding{Path: "required_pull_request_reviews", Message: fmt.Sprintf("%s requires %d approving reviews, at least %d are expected", branch, reviews, want)})
+	}
+
+	var contexts []string
+	if c := protection.RequiredStatusChecks; c != nil {
+		contexts = c.Contexts
+	}
+	if len(contexts) == 0 {
+		observed = append(observed, "no status checks")
+		findings = append(findings, Finding{Path: "required_status_checks", Message: fmt.Sprintf("%s requires no status checks to pass", branch)})
+	} else {
+		observed = append(observed, "status checks "+strings.Join(contexts, " "))
+		if protection.RequiredStatusChecks.Strict {
+			observed = append(observed, "up to date before merging")
+		}
+	}
+
+	if protection.AllowForcePushes.Enabled {
+		observed = append(observed, "force pushes allowed")
+		findings = append(findings, Finding{Path: "allow_force_pushes", Message: fmt.Sprintf("%s allows force pushes", branch)})
+	} else {
+		observed = append(observed, "force pushes blocked")
+	}
+	reality := branch + ": " + strings.Join(observed, ", ")
+
+	if len(findings) > 0 {
+		s.Score--
+		slog.Warn("Branch Protection Findings", slog.String("Repo", s.Repo), slog.String("Protection", reality), slog.Int("Score", s.Score))
+		return &TestReturn{Present: true, Owner: s.Owner, Reality: reality, Source: branch, Score: s.Score, Findings: findings}
+	}
+
+	slog.Info("Branch Protected", slog.String("Repo", s.Repo), slog.String("Protection", reality), slog.Int("Score", s.Score))
+	return &TestReturn{Present: true, Owner: s.Owner, Reality: reality, Source: branch, Works: true, Score: s.Score}
+}
+
+var (
+	errBranchUnprotected = errors.New("branch isn't protected")
+	errProtectionHidden  = errors.New("branch protection can't be read")
+)
+
+// readBranchProtection fetches the protection of the repository's default branch.
+// Whether the branch is protected is read from the branch, which needs only read access.
+// Its settings need administration access, without it GitHub answers 404 as if the branch were unprotected,
+// so an unprotected branch is errBranchUnprotected and settings that can't be read are errProtectionHidden.
+// Any other error, e.g.: GitHub failing to answer, is returned as it is.
+func (s *SvcTestDB) readBranchProtection() (string, *ghBranchProtection, error) {
+	gh := s.github()
+
+	body, err := gh.Get(urlCat(gh.BaseURL, ghPreURI, s.Repo))
+	if err != nil {
+		return "", nil, err
+	}
+	var repo ghRepository
+	if err := json.Unmarshal([]byte(body), &repo); err != nil {
+		return "", nil, fmt.Errorf("problem parsing repository, %v", err)
+	}
+	if repo.DefaultBranch == "" {
+		return "", nil, errors.New("repository has no default branch")
+	}
+
+	body, err = gh.Get(urlCat(gh.BaseURL, ghPreURI, s.Repo, ghBranchesPATH, repo.DefaultBranch))
+	if err != nil {
+		return repo.DefaultBranch, nil, err
+	}
+	var branch ghBranch
+	if err := json.Unmarshal([]byte(body), &branch); err != nil {
+		return repo.DefaultBranch, nil, fmt.Errorf("problem parsing branch, %v", err)
+	}
+	if !branch.Protected {
+		return repo.DefaultBranch, nil, errBranchUnprotected
+	}
+
+	body, err = gh.Get(urlCat(gh.BaseURL, ghPreURI, s.Repo, ghBranchesPATH, repo.DefaultBranch, "/protection"))
+	if errors.Is(err, GitHubNotFound) || errors.Is(err, GitHubForbidden) {
+		return repo.DefaultBranch, nil, fmt.Errorf("%w, %v", errProtectionHidden, err)
+	}
+	if err != nil {
+		return repo.DefaultBranch, nil, err
+	}
+	var protection ghBranchProtection
+	if err := json.Unmarshal([]byte(body), &protection); err != nil {
+		return repo.DefaultBranch, nil, fmt.Errorf("problem parsing branch protection, %v", err)
+	}
+	return repo.DefaultBranch, &protection, nil
+}
@@ -0,0 +1,146 @@
+package main
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+// The protection observed is the Reality, and each setting short of it is a Finding
+func TestBranchProtectionCheck(t *testing.T) {
+	gh, fake := newFakeGitHub(t, fakeGitHubFixtures)
+
+	protectionTests := []struct {
+		Repo     string
+		Works    bool
+		Reality  string
+		Findings []Finding
+	}{
+		{"GhostGroup/weedmaps", true, "main: 2 approving reviews, code owner review, status checks ci/test ci/lint, up to date before merging, force pushes blocked", nil},
+		{"GhostGroup/admin", false, "main: 0 approving reviews, no status checks, force pushes allowed", []Finding{
+			{Path: "required_pull_request_reviews", Message: "main requires 0 approving reviews, at least 1 are expected"},
+			{Path: "required_status_checks", Message: "main requires no status checks to pass"},
+			{Path: "allow_force_pushes", Message: "main allows force pushes"},
+		}},
+		{"GhostGroup/ad-server", false, "main isn't protected", []Finding{{Path: "branches/main", Message: "main isn't protected"}}},
+		{"", true, repoUnresolved, nil}, // The repository Check reports it
+	}
+
+	for _, tt := range protectionTests {
+		t.Run(tt.Repo, func(t *testing.T) {
+			s := &SvcTestDB{Repo: tt.Repo, GitHub: gh, Score: 100}
+			got := branchProtectionCheck{}.TestItem(s)
+
+			assertBool(t, got.Works, tt.Works)
+			assertString(t, got.Reality, tt.Reality)
+			if diff := cmp.Diff(got.Findings, tt.Findings); diff != "" {
+				t.Error(diff)
+			}
+			assertCharged(t, s, tt.Works)
+		})
+	}
+
+	t.Run("reviews needed come from the environment", func(t *testing.T) {
+		t.Setenv("BRANCH_MIN_REVIEWS", "3")
+		s := &SvcTestDB{Repo: "GhostGroup/weedmaps", GitHub: gh, Score: 100}
+		got := branchProtectionCheck{}.TestItem(s)
+		want := []Finding{{Path: "required_pull_request_reviews", Message: "main requires 2 approving reviews, at least 3 are expected"}}
+		if diff := cmp.Diff(got.Findings, want); diff != "" {
+			t.Error(diff)
+		}
+	})
+
+	t.Run("protection is read from the default branch", func(t *testing.T) {
+		before := len(fake.Requests())
+		s := &SvcTestDB{Repo: "GhostGroup/weedmaps", GitHub: gh, Score: 100}
+		branchProtectionCheck{}.TestItem(s)
+		want := []string{"/repos/GhostGroup/weedmaps", "/repos/GhostGroup/weedmaps/branches/main", "/repos/GhostGroup/weedmaps/branches/main/protection"}
+		if diff := cmp.Diff(fake.Requests()[before:], want); diff != "" {
+			t.Error(diff)
+		}
+	})
+
+	t.Run("protection hidden from the token isn't held against the Service", func(t *testing.T) {
+		gh, fake := newFakeGitHub(t, fakeGitHubFixtures)
+		fake.HideProtection()
+
+		s := &SvcTestDB{Repo: "GhostGroup/weedmaps", GitHub: gh, Score: 100}
+		got := branchProtectionCheck{}.TestItem(s)
+		assertBool(t, got.Works, true)
+		assertString(t, got.Reality, "main is protected, its settings can't be read")
+		assertScore(t, s.Score, 100)
+
+		// An unprotected branch is still told apart
+		s = &SvcTestDB{Repo: "GhostGroup/ad-server", GitHub: gh, Score: 100}
+		got = branchProtectionCheck{}.TestItem(s)
+		assertBool(t, got.Works, false)
+		assertString(t, got.Reality, "main isn't protected")
+		assertScore(t, s.Score, 99)
+	})
+
+	t.Run("GitHub failing isn't hidden protection", func(t *testing.T) {
+		gh, fake := newFakeGitHub(t, fakeGitHubFixtures)
+		fake.FailProtection()
+
+		s := &SvcTestDB{Repo: "GhostGroup/weedmaps", GitHub: gh, Score: 100}
+		got := branchProtectionCheck{}.TestItem(s)
+		assertBool(t, got.Works, false)
+		assertError(t, s.Outage(), errGitHubTransient)
+		assertScore(t, s.Score, 100)
+	})
+
+	t.Run("a token that can't read the protection is hidden from", func(t *testing.T) {
+		gh, _, _ := newTestGitHubClient(t,
+			answer(http.StatusOK, `{"default_branch": "main"}`),
+			answer(http.StatusOK, `{"protected": true}`),
+			answer(http.StatusForbidden, ""),
+		)
+
+		s := &SvcTestDB{Repo: "GhostGroup/weedmaps", GitHub: gh, Score: 100}
+		got := branchProtectionCheck{}.TestItem(s)
+		assertBool(t, got.Works, true)
+		assertString(t, got.Reality, "main is protected, its settings can't be read")
+		assertError(t, s.Outage(), nil)
+	})
+}
+
+// The governance files are probed like the documentation
+func TestGovernanceChecks(t *testing.T) {
+	gh, _ := newFakeGitHub(t, fakeGitHubFixtures)
+
+	governanceTests := []struct {
+		Check  string
+		Repo   string
+		Works  bool
+		Source string
+	}{
+		{"security-policy", "GhostGroup/weedmaps", true, "SECURITY.md"},
+		{"security-policy", "GhostGroup/admin", false, ""},
+		{"license", "GhostGroup/weedmaps", true, "LICENSE"},
+		{"license", "GhostGroup/admin", true, "LICENSE.md"},
+		{"license", "GhostGroup/ad-server", false, ""},
+		{"dependency-updates", "GhostGroup/weedmaps", true, ".github/dependabot.yml"},
+		{"dependency-updates", "GhostGroup/ad-server", true, "renovate.json5"},
+		{"dependency-updates", "GhostGroup/admin", false, ""},
+		{"license", "", true, ""}, // The repository Check reports it
+	}
+
+	for _, tt := range governanceTests {
+		t.Run(tt.Check+" in "+tt.Repo, func(t *testing.T) {
+			s := &SvcTestDB{Repo: tt.Repo, GitHub: gh, Score: 100}
+			c, ok := LookupCheck(tt.Check)
+			if !ok {
+				t.Fatalf("no Check %q", tt.Check)
+			}
+			got := c.TestItem(s)
+
+			if got.Works != tt.Works || got.Source != tt.Source {
+				t.Errorf("got works %t from %q want works %t from %q", got.Works, got.Source, tt.Works, tt.Source)
+			}
+			if !tt.Works && len(got.Findings) == 0 {
+				t.Error("wanted a Finding for each path probed")
+			}
+		})
+	}
+}
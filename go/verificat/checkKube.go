@@ -0,0 +1,175 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"log/slog"
+	"strings"
+)
+
+// kubeCheck is the Verification of one aspect of how a Service runs on Kubernetes,
+// read from the manifests and Helm values in its repository.
+// The manifests are read once per run and every kubeCheck inspects each workload in turn,
+// giving each workload, and each container in it, its own Finding.
+type kubeCheck struct {
+	CheckInfo
+	inspect func(w Workload) []Finding // The problems with a single workload
+}
+
+// kubeChecks are the Stability, Scalability and Fault Tolerance evidence in the manifests.
+var kubeChecks = []kubeCheck{
+	{
+		CheckInfo: CheckInfo{
+			ID:          "probes",
+			Description: "Kubernetes containers declare liveness and readiness probes",
+			Principles:  []Principle{Stability, FaultTolerance, Monitoring},
+		},
+		inspect: inspectProbes,
+	},
+	{
+		CheckInfo: CheckInfo{
+			ID:          "resources",
+			Description: "Kubernetes containers request CPU and memory, and limit memory",
+			Principles:  []Principle{Stability, Scalability, Performance},
+		},
+		inspect: inspectResources,
+	},
+	{
+		CheckInfo: CheckInfo{
+			ID:          "replicas",
+			Description: "Kubernetes workloads run more than one replica",
+			Principles:  []Principle{FaultTolerance, Scalability},
+		},
+		inspect: inspectReplicas,
+	},
+	{
+		CheckInfo: CheckInfo{
+			ID:          "disruption-budget",
+			Description: "Kubernetes workloads are covered by a PodDisruptionBudget",
+			Principles:  []Principle{FaultTolerance, CatastrophePreparedness},
+		},
+		inspect: inspectBudget,
+	},
+}
+
+func init() {
+	for _, c := range kubeChecks {
+		RegisterCheck(c)
+	}
+}
+
+// TestItem inspects every workload, from manifests and Helm values alike, costing a point if any has a problem.
+// A Service without workloads in its repository isn't judged, e.g.: a database Resource.
+// Manifests that can't be read cost a single point, taken by the first Check to read them.
+// GitHub failing to answer costs nothing, the run is tried again.
+func (c kubeCheck) TestItem(s *SvcTestDB) *TestReturn {
+	m, err := s.readManifests()
+	if errors.Is(err, errRepoUnresolved) {
+		return &TestReturn{Owner: s.Owner, Reality: repoUnresolved, Works: true, Score: s.Score}
+	}
+	if s.unavailable(err) {
+		return &TestReturn{Owner: s.Owner, Reality: err.Error(), Score: s.Score}
+	}
+	if err != nil && s.manifests.charged != "" {
+		return &TestReturn{Owner: s.Owner, Reality: "manifests unreadable, reported by " + s.manifests.charged, Score: s.Score}
+	}
+	if err != nil {
+		s.Score--
+		s.manifests.charged = c.ID
+		slog.Warn("Unreadable Manifests", slog.String("Check", c.ID), slog.String("Repo", s.Repo), slog.Any("Error", err), slog.Int("Score", s.Score))
+		return &TestReturn{Owner: s.Owner, Reality: err.Error(), Score: s.Score, Findings: []Finding{{Message: err.Error()}}}
+	}
+	if len(m.Workloads) == 0 {
+		return &TestReturn{Owner: s.Owner, Reality: "no Deployments, StatefulSets or Helm values found", Works: true, Score: s.Score}
+	}
+
+	var names []string
+	var findings []Finding
+	for _, w := range m.Workloads {
+		names = append(names, w.String())
+		findings = append(findings, c.inspect(w)...)
+	}
+	reality := strings.Join(names, " ")
+	source := strings.Join(m.Files, " ")
+
+	if len(findings) > 0 {
+		s.Score--
+		slog.Warn("Manifest Findings", slog.String("Check", c.ID), slog.String("Repo", s.Repo), slog.Int("Findings", len(findings)), slog.Int("Score", s.Score))
+		return &TestReturn{Present: true, Owner: s.Owner, Reality: reality, Source: source, Score: s.Score, Findings: findings}
+	}
+
+	slog.Info("Manifests Ready", slog.String("Check", c.ID), slog.String("Repo", s.Repo), slog.Int("Workloads", len(m.Workloads)), slog.Int("Score", s.Score))
+	return &TestReturn{Present: true, Owner: s.Owner, Reality: reality, Source: source, Works: true, Score: s.Score}
+}
+
+// inspectProbes wants each container to say when it's alive and when it's ready for traffic.
+func inspectProbes(w Workload) []Finding {
+	var findings []Finding
+	for _, c := range w.Containers {
+		if !c.Liveness {
+			findings = append(findings, Finding{Path: w.Source, Line: c.Line, Message: fmt.Sprintf("%s container %s has no %s", w, c.Name, w.Setting("livenessProbe"))})
+		}
+		if !c.Readiness {
+			findings = append(findings, Finding{Path: w.Source, Line: c.Line, Message: fmt.Sprintf("%s container %s has no %s", w, c.Name, w.Setting("readinessProbe"))})
+		}
+	}
+	return findings
+}
+
+// inspectResources wants each container to request CPU and memory, so it's scheduled somewhere it fits,
+// and to limit memory, so a leak takes down the container rather than its neighbours.
+// CPU limits are left alone, they throttle rather than protect.
+func inspectResources(w Workload) []Finding {
+	var findings []Finding
+	for _, c := range w.Containers {
+		for _, want := range []struct {
+			field string
+			set   map[string]string
+			name  string
+		}{
+			{"requests.cpu", c.Requests, "cpu"},
+			{"requests.memory", c.Requests, "memory"},
+			{"limits.memory", c.Limits, "memory"},
+		} {
+			if want.set[want.name] == "" {
+				field := w.Setting("resources") + "." + want.field
+				findings = append(findings, Finding{Path: w.Source, Line: c.Line, Message: fmt.Sprintf("%s container %s has no %s", w, c.Name, field)})
+			}
+		}
+	}
+	return findings
+}
+
+// inspectReplicas wants a workload to survive losing a pod.
+// A Chart's Finding names the value to raise.
+func inspectReplicas(w Workload) []Finding {
+	n := w.MinReplicas()
+	if n >= 2 {
+		return nil
+	}
+
+	var message string
+	switch {
+	case w.Settings != nil && w.Autoscaler != nil:
+		message = fmt.Sprintf("%s scales down to %d replica, set by %s", w, n, w.Setting("minReplicas"))
+	case w.Settings != nil:
+		message = fmt.Sprintf("%s runs %d replica, set by %s", w, n, w.Setting("replicas"))
+	case w.Autoscaler != nil:
+		message = fmt.Sprintf("%s scales down to %d replica with HorizontalPodAutoscaler/%s", w, n, w.Autoscaler.Name)
+	default:
+		message = fmt.Sprintf("%s runs %d replica", w, n)
+	}
+	return []Finding{{Path: w.Source, Line: w.Line, Message: message}}
+}
+
+// inspectBudget wants a workload's pods kept up through node drains and upgrades.
+func inspectBudget(w Workload) []Finding {
+	if w.Budget != "" {
+		return nil
+	}
+	message := fmt.Sprintf("%s has no PodDisruptionBudget selecting its pods", w)
+	if w.Settings != nil {
+		message = fmt.Sprintf("%s has no PodDisruptionBudget, enabled by %s", w, w.Setting("podDisruptionBudget"))
+	}
+	return []Finding{{Path: w.Source, Line: w.Line, Message: message}}
+}
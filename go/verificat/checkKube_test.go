@@ -0,0 +1,127 @@
+package main
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+// Each workload and container gets its own Finding, at its line in the manifest
+func TestKubeChecks(t *testing.T) {
+	gh, _ := newFakeGitHub(t, fakeGitHubFixtures)
+	const bidder = "deploy/bidder.yaml"
+
+	kubeTests := []struct {
+		Check    string
+		Repo     string
+		Works    bool
+		Findings []Finding
+	}{
+		{"probes", "GhostGroup/weedmaps", true, nil},
+		{"resources", "GhostGroup/weedmaps", true, nil},
+		{"replicas", "GhostGroup/weedmaps", true, nil},
+		{"disruption-budget", "GhostGroup/weedmaps", true, nil},
+		{"probes", "GhostGroup/ad-server", false, []Finding{
+			{Path: bidder, Line: 29, Message: "Deployment/bidder container statsd has no livenessProbe"},
+			{Path: bidder, Line: 29, Message: "Deployment/bidder container statsd has no readinessProbe"},
+		}},
+		{"resources", "GhostGroup/ad-server", false, []Finding{
+			{Path: bidder, Line: 15, Message: "Deployment/bidder container bidder has no resources.limits.memory"},
+			{Path: bidder, Line: 29, Message: "Deployment/bidder container statsd has no resources.requests.cpu"},
+			{Path: bidder, Line: 29, Message: "Deployment/bidder container statsd has no resources.requests.memory"},
+			{Path: bidder, Line: 29, Message: "Deployment/bidder container statsd has no resources.limits.memory"},
+		}},
+		{"replicas", "GhostGroup/ad-server", false, []Finding{
+			{Path: bidder, Line: 1, Message: "Deployment/bidder scales down to 1 replica with HorizontalPodAutoscaler/bidder"},
+		}},
+		{"probes", "GhostGroup/admin", false, []Finding{
+			{Path: "helm/values.yaml", Message: "Chart/admin container admin has no livenessProbe"},
+			{Path: "helm/values.yaml", Message: "Chart/admin container admin has no readinessProbe"},
+		}},
+		{"resources", "GhostGroup/admin", false, []Finding{
+			{Path: "helm/values.yaml", Message: "Chart/admin container admin has no resources.requests.cpu"},
+			{Path: "helm/values.yaml", Message: "Chart/admin container admin has no resources.requests.memory"},
+			{Path: "helm/values.yaml", Message: "Chart/admin container admin has no resources.limits.memory"},
+		}},
+		{"replicas", "GhostGroup/admin", false, []Finding{
+			{Path: "k8s/admin.yaml", Line: 4, Message: "Deployment/admin runs 1 replica"},
+			{Path: "helm/values.yaml", Message: "Chart/admin runs 1 replica, set by replicaCount"},
+		}},
+		{"disruption-budget", "GhostGroup/admin", false, []Finding{
+			{Path: "k8s/admin.yaml", Line: 4, Message: "Deployment/admin has no PodDisruptionBudget selecting its pods"},
+			{Path: "helm/values.yaml", Message: "Chart/admin has no PodDisruptionBudget, enabled by podDisruptionBudget"},
+		}},
+	}
+
+	for _, tt := range kubeTests {
+		t.Run(tt.Check+" in "+tt.Repo, func(t *testing.T) {
+			s := &SvcTestDB{Repo: tt.Repo, GitHub: gh, Score: 100}
+			c, ok := LookupCheck(tt.Check)
+			if !ok {
+				t.Fatalf("no Check %q", tt.Check)
+			}
+			got := c.TestItem(s)
+
+			if got.Works != tt.Works || !got.Present {
+				t.Errorf("got works %t, present %t want works %t", got.Works, got.Present, tt.Works)
+			}
+			if diff := cmp.Diff(got.Findings, tt.Findings); diff != "" {
+				t.Error(diff)
+			}
+			assertCharged(t, s, tt.Works)
+		})
+	}
+
+	t.Run("no workloads isn't judged", func(t *testing.T) {
+		t.Setenv("KUBE_PATHS", "helm")
+		t.Setenv("HELM_VALUES_PATHS", "charts/values.yaml")
+		probes, _ := LookupCheck("probes")
+		for _, repo := range []string{"GhostGroup/weedmaps", ""} {
+			s := &SvcTestDB{Repo: repo, GitHub: gh, Score: 100}
+			got := probes.TestItem(s)
+			if !got.Works || got.Present || len(got.Findings) > 0 {
+				t.Errorf("%q got works %t, present %t, findings %v", repo, got.Works, got.Present, got.Findings)
+			}
+			assertScore(t, s.Score, 100)
+		}
+	})
+
+	t.Run("unreadable manifests cost one point", func(t *testing.T) {
+		forbidden, _, _ := newTestGitHubClient(t, answer(http.StatusForbidden, ""))
+		s := &SvcTestDB{Repo: "GhostGroup/admin", GitHub: forbidden, Score: 100}
+		var reported int
+		for _, c := range kubeChecks {
+			got := c.TestItem(s)
+			if got.Works {
+				t.Errorf("%s got a pass from unreadable manifests", c.ID)
+			}
+			reported += len(got.Findings)
+		}
+		assertIDEquals(t, reported, 1)
+		assertScore(t, s.Score, 99)
+	})
+
+	t.Run("GitHub failing to answer costs nothing", func(t *testing.T) {
+		down, _, _ := newTestGitHubClient(t, answer(http.StatusServiceUnavailable, ""))
+		s := &SvcTestDB{Repo: "GhostGroup/admin", GitHub: down, Score: 100}
+		for _, c := range kubeChecks {
+			c.TestItem(s)
+		}
+		assertScore(t, s.Score, 100)
+		assertError(t, s.Outage(), errGitHubTransient)
+	})
+
+	t.Run("manifests are fetched once per run", func(t *testing.T) {
+		gh, fake := newFakeGitHub(t, fakeGitHubFixtures)
+		s := &SvcTestDB{Repo: "GhostGroup/weedmaps", GitHub: gh, Score: 100}
+		for _, c := range kubeChecks {
+			c.TestItem(s)
+		}
+		first := len(fake.Requests())
+		for _, c := range kubeChecks {
+			c.TestItem(s)
+		}
+		assertIDEquals(t, len(fake.Requests()), first)
+	})
+}
@@ -0,0 +1,159 @@
+package main
+
+import (
+	"fmt"
+	"log/slog"
+	"strings"
+)
+
+const techdocsRef = "backstage.io/techdocs-ref"
+
+// defaultAnnotations are the annotations every catalog entry needs unless REQUIRED_ANNOTATIONS says otherwise:
+// where its TechDocs are, who gets paged, and where its dashboards are.
+var defaultAnnotations = []string{techdocsRef, "pagerduty.com/service-id", "datadoghq.com/dashboard-url"}
+
+// metadataCheck is the Validation of one field of the catalog entry:
+// it's filled in, whatever it says.
+// Each field fails on its own, so an owner sees which one to fill in.
+type metadataCheck struct {
+	CheckInfo
+	path string                            // The field, e.g.: metadata.description
+	read func(s *SvcTestDB) (string, bool) // What the field holds, and whether that counts as filled in
+}
+
+// metadataChecks are the fields a catalog entry needs besides its owner.
+var metadataChecks = []metadataCheck{
+	{
+		CheckInfo: CheckInfo{
+			ID:          "description",
+			Description: "Backstage description says what the service is",
+			Principles:  []Principle{Documentation},
+		},
+		path: "metadata.description",
+		read: func(s *SvcTestDB) (string, bool) {
+			return s.Metadata.Description, strings.TrimSpace(s.Metadata.Description) != ""
+		},
+	},
+	{
+		CheckInfo: CheckInfo{
+			ID:          "system",
+			Description: "Backstage System is set on Components, Resources and APIs",
+			Principles:  []Principle{Reliability, Documentation},
+		},
+		path: "spec.system",
+		read: func(s *SvcTestDB) (string, bool) {
+			// A System is its own System
+			if s.Kind == "" || s.Kind == KindSystem {
+				return s.Service, true
+			}
+			return s.System, s.System != ""
+		},
+	},
+	{
+		CheckInfo: CheckInfo{
+			ID:          "tags",
+			Description: "Backstage tags are present",
+			Principles:  []Principle{Documentation},
+		},
+		path: "metadata.tags",
+		read: func(s *SvcTestDB) (string, bool) {
+			return strings.Join(s.Metadata.Tags, " "), len(s.Metadata.Tags) > 0
+		},
+	},
+	{
+		CheckInfo: CheckInfo{
+			ID:          "links",
+			Description: "Backstage links point somewhere",
+			Principles:  []Principle{Documentation, CatastrophePreparedness},
+		},
+		path: "metadata.links",
+		read: func(s *SvcTestDB) (string, bool) {
+			var urls []string
+			for _, l := range s.Metadata.Links {
+				if l.URL != "" {
+					urls = append(urls, l.URL)
+				}
+			}
+			return strings.Join(urls, " "), len(urls) > 0
+		},
+	},
+}
+
+func init() {
+	for _, c := range metadataChecks {
+		RegisterCheck(c)
+	}
+	RegisterCheck(annotationsCheck{})
+}
+
+// TestItem is returning a test result to ReadinessDisplay
+// A missing field costs a point, and the Finding is the path of the field.
+func (c metadataCheck) TestItem(s *SvcTestDB) *TestReturn {
+	if s.Metadata == nil {
+		// Nothing was read from the catalog, e.g.: a run without Backstage
+		return &TestReturn{Owner: s.Owner, Reality: "not read from the catalog", Works: true, Score: s.Score}
+	}
+
+	reality, present := c.read(s)
+	if !present {
+		s.Score--
+		slog.Warn("Empty Field", slog.String("Service", s.Service), slog.String("Field", c.path), slog.Int("Score", s.Score))
+		return &TestReturn{Owner: s.Owner, Reality: reality, Source: c.path, Score: s.Score,
+			Findings: []Finding{{Path: c.path, Message: c.path + " is empty"}}}
+	}
+
+	slog.Info("Present Field", slog.String("Service", s.Service), slog.String("Field", c.path), slog.Int("Score", s.Score))
+	return &TestReturn{Present: true, Owner: s.Owner, Reality: reality, Source: c.path, Works: true, Score: s.Score}
+}
+
+// annotationsCheck is the Validation of the annotations other Backstage plugins read,
+// e.g.: TechDocs, PagerDuty and Datadog.
+type annotationsCheck struct{}
+
+func (annotationsCheck) Info() CheckInfo {
+	return CheckInfo{
+		ID:          "annotations",
+		Description: "Backstage annotations for TechDocs, on-call and dashboards are present",
+		Principles:  []Principle{Monitoring, CatastrophePreparedness, Documentation},
+	}
+}
+
+// requiredAnnotations reads REQUIRED_ANNOTATIONS, a comma-separated list of annotation keys.
+// Unset keeps defaultAnnotations.
+func requiredAnnotations() []string {
+	return envList("REQUIRED_ANNOTATIONS", defaultAnnotations)
+}
+
+// annotationPath is where an annotation lives in the entity,
+// bracketed since the keys have dots and slashes of their own.
+func annotationPath(key string) string {
+	return fmt.Sprintf("metadata.annotations[%q]", key)
+}
+
+// TestItem reports every missing annotation as its own Finding,
+// at the cost of a single point however many are missing.
+func (annotationsCheck) TestItem(s *SvcTestDB) *TestReturn {
+	if s.Metadata == nil {
+		return &TestReturn{Owner: s.Owner, Reality: "not read from the catalog", Works: true, Score: s.Score}
+	}
+
+	var present []string
+	var findings []Finding
+	for _, key := range requiredAnnotations() {
+		if strings.TrimSpace(s.Metadata.Annotations[key]) == "" {
+			findings = append(findings, Finding{Path: annotationPath(key), Message: fmt.Sprintf("annotation %s is missing", key)})
+			continue
+		}
+		present = append(present, key)
+	}
+	reality := strings.Join(present, " ")
+
+	if len(findings) > 0 {
+		s.Score--
+		slog.Warn("Missing Annotations", slog.String("Service", s.Service), slog.Int("Missing", len(findings)), slog.Int("Score", s.Score))
+		return &TestReturn{Present: len(present) > 0, Owner: s.Owner, Reality: reality, Score: s.Score, Findings: findings}
+	}
+
+	slog.Info("Annotations Present", slog.String("Service", s.Service), slog.String("Annotations", reality), slog.Int("Score", s.Score))
+	return &TestReturn{Present: true, Owner: s.Owner, Reality: reality, Works: true, Score: s.Score}
+}
@@ -0,0 +1,94 @@
+package main
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/tdabasinskas/go-backstage/v2/backstage"
+)
+
+// Each metadata field is its own Check, pointing at the field that's empty
+func TestMetadataChecks(t *testing.T) {
+	complete := &backstage.EntityMeta{
+		Name:        "core-braze",
+		Description: "Sends core's customer events to Braze",
+		Tags:        []string{"ruby"},
+		Links:       []backstage.EntityLink{{URL: "https://www.braze.com/docs"}},
+	}
+	empty := &backstage.EntityMeta{Name: "core-braze", Description: "  ", Links: []backstage.EntityLink{{Title: "nowhere"}}}
+
+	for _, c := range metadataChecks {
+		t.Run(c.ID+" present", func(t *testing.T) {
+			s := &SvcTestDB{Service: "component:core-braze", Kind: KindComponent, System: "core", Metadata: complete, Score: 100}
+			got := c.TestItem(s)
+			if !got.Works || !got.Present || s.Score != 100 {
+				t.Errorf("got %+v want a pass", got)
+			}
+		})
+
+		t.Run(c.ID+" missing", func(t *testing.T) {
+			s := &SvcTestDB{Service: "component:core-braze", Kind: KindComponent, Metadata: empty, Score: 100}
+			got := c.TestItem(s)
+			if got.Works || got.Present || s.Score != 99 {
+				t.Errorf("got %+v want a failure costing a point", got)
+			}
+			if diff := cmp.Diff(got.Findings, []Finding{{Path: c.path, Message: c.path + " is empty"}}); diff != "" {
+				t.Error(diff)
+			}
+		})
+	}
+
+	t.Run("a System doesn't need a System", func(t *testing.T) {
+		system, _ := LookupCheck("system")
+		got := system.TestItem(&SvcTestDB{Service: "core", Metadata: empty, Score: 100})
+		if !got.Works {
+			t.Errorf("got %+v want a pass", got)
+		}
+	})
+
+	t.Run("nothing is checked without the catalog", func(t *testing.T) {
+		s := &SvcTestDB{Service: "core", Score: 100}
+		for _, c := range append([]Check{annotationsCheck{}}, metadataChecks[0]) {
+			if got := c.TestItem(s); !got.Works || s.Score != 100 {
+				t.Errorf("got %+v from %s want a pass without penalty", got, c.Info().ID)
+			}
+		}
+	})
+}
+
+// Every missing annotation is its own Finding, for a single point
+func TestAnnotationsCheck(t *testing.T) {
+	meta := &backstage.EntityMeta{Name: "core", Annotations: map[string]string{
+		techdocsRef:                "dir:.",
+		"pagerduty.com/service-id": "PCORE01",
+	}}
+
+	t.Run("the defaults want TechDocs, PagerDuty and Datadog", func(t *testing.T) {
+		s := &SvcTestDB{Service: "core", Metadata: meta, Score: 100}
+		got := annotationsCheck{}.TestItem(s)
+
+		want := []Finding{{Path: `metadata.annotations["datadoghq.com/dashboard-url"]`, Message: "annotation datadoghq.com/dashboard-url is missing"}}
+		if diff := cmp.Diff(got.Findings, want); diff != "" {
+			t.Error(diff)
+		}
+		if got.Works || s.Score != 99 {
+			t.Errorf("got %+v want a failure costing a point", got)
+		}
+		assertString(t, got.Reality, techdocsRef+" pagerduty.com/service-id")
+	})
+
+	t.Run("REQUIRED_ANNOTATIONS replaces the defaults", func(t *testing.T) {
+		t.Setenv("REQUIRED_ANNOTATIONS", " pagerduty.com/service-id, backstage.io/techdocs-ref ,")
+		s := &SvcTestDB{Service: "core", Metadata: meta, Score: 100}
+		got := annotationsCheck{}.TestItem(s)
+		if !got.Works || len(got.Findings) != 0 || s.Score != 100 {
+			t.Errorf("got %+v want a pass", got)
+		}
+
+		t.Setenv("REQUIRED_ANNOTATIONS", "opsgenie.com/team,sentry.io/project-slug")
+		got = annotationsCheck{}.TestItem(s)
+		if len(got.Findings) != 2 || got.Present {
+			t.Errorf("got %+v want both missing", got)
+		}
+	})
+}
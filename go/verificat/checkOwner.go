@@ -0,0 +1,81 @@
+package main
+
+import (
+	"fmt"
+	"log/slog"
+)
+
+// ownerCheck is the "Owner" test between Backstage and GitHub.
+type ownerCheck struct{}
+
+func init() {
+	RegisterCheck(ownerCheck{})
+}
+
+func (ownerCheck) Info() CheckInfo {
+	return CheckInfo{
+		ID:          "owner",
+		Description: "Backstage Owner is present and matches GitHub CODEOWNERS",
+		Principles:  []Principle{Reliability, CatastrophePreparedness},
+		Required:    true,
+	}
+}
+
+// TestItem is returning a test result to ReadinessDisplay
+// Currently this represents the "Owner" test between Backstage and GitHub
+func (ownerCheck) TestItem(s *SvcTestDB) *TestReturn {
+	// Check the owner field.
+	// Validation: If it's populated, return true.
+	// Verification: If it's populated with the correct string, return true.
+
+	var present, works bool
+
+	// Get the actual value from CODEOWNERS in the matching GitHub repos:
+	// whoever owns the repository root.
+	// Without a repository or a CODEOWNERS file there's nothing to compare against,
+	// the "repository" and "codeowners" Checks report why and carry the point.
+	var owners []CodeOwner
+	var findings []Finding
+	co, path, err := s.readCodeowners()
+	if s.unavailable(err) {
+		return &TestReturn{Present: s.Owner != "", Owner: s.Owner, Reality: err.Error(), Source: path, Score: s.Score}
+	}
+	if err == nil {
+		owners = co.RootOwners()
+	}
+	reality := ownerNames(owners)
+
+	// Check the Owner for any WMService in Backstage
+	if s.Owner == "" {
+		// Validation has failed, the field is empty
+		present = false
+		s.Score--
+		slog.Warn("Empty Field", slog.String("Owner", s.Owner))
+		// Verification automatically fails
+		s.Score--
+		slog.Info("New Adjustment", slog.Int("Score", s.Score))
+	} else {
+		// Validation succeeds!
+		present = true
+		// Now check if it is equal to the retrieved source of truth
+		if !ownerMatches(s.Owner, owners) {
+			// Verification has failed
+			works = false
+			if err != nil {
+				slog.Warn("Unverified Field", slog.String("Owner", s.Owner), slog.Any("Error", err))
+			} else {
+				findings = append(findings, Finding{Path: path, Message: fmt.Sprintf("owner %q is not a root owner in CODEOWNERS (%s)", s.Owner, reality)})
+				slog.Warn("Unequal Field", slog.String("Owner", s.Owner), slog.String("Reality", reality))
+				s.Score--
+				slog.Info("New Adjustment", slog.Int("Score", s.Score))
+			}
+		} else {
+			// Verification succeeds!
+			works = true
+			slog.Info("Matching Field", slog.String("Owner", s.Owner), slog.String("Reality", reality), slog.Int("Score", s.Score))
+		}
+	}
+
+	// This will be included in the API return value
+	return &TestReturn{Present: present, Owner: s.Owner, Reality: reality, Source: path, Works: works, Score: s.Score, Findings: findings}
+}
@@ -0,0 +1,35 @@
+package main
+
+import "log/slog"
+
+// repoUnresolved is the Reality reported when no GitHub repository could be found.
+const repoUnresolved = "repository unresolved"
+
+// repoCheck verifies the Service's code can be found in GitHub,
+// which every GitHub-backed Check relies on.
+type repoCheck struct{}
+
+func init() {
+	RegisterCheck(repoCheck{})
+}
+
+func (repoCheck) Info() CheckInfo {
+	return CheckInfo{
+		ID:          "repository",
+		Description: "GitHub repository resolves from the Backstage project-slug annotation or the System name",
+		Principles:  []Principle{CatastrophePreparedness, Documentation},
+		Required:    true,
+	}
+}
+
+// TestItem fails when neither the github.com/project-slug annotation
+// nor a repository named after the System exists.
+func (repoCheck) TestItem(s *SvcTestDB) *TestReturn {
+	if s.Repo == "" {
+		s.Score--
+		slog.Warn("Repository Unresolved", slog.String("Service", s.Service), slog.Int("Score", s.Score))
+		return &TestReturn{Present: false, Owner: s.Owner, Reality: repoUnresolved, Works: false, Score: s.Score}
+	}
+
+	return &TestReturn{Present: true, Owner: s.Owner, Reality: s.Repo, Works: true, Score: s.Score}
+}
@@ -0,0 +1,42 @@
+package main
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestRepoCheck(t *testing.T) {
+	t.Run("a resolved repository passes", func(t *testing.T) {
+		stests := &SvcTestDB{Service: "core", Owner: "code-owners-core", Repo: "GhostGroup/weedmaps", Score: 100}
+
+		got := repoCheck{}.TestItem(stests)
+		want := &TestReturn{Present: true, Owner: "code-owners-core", Reality: "GhostGroup/weedmaps", Works: true, Score: 100}
+
+		if diff := cmp.Diff(got, want); diff != "" {
+			t.Error(diff)
+		}
+	})
+
+	t.Run("an unresolved repository fails the Required Baseline", func(t *testing.T) {
+		stests := &SvcTestDB{Owner: "code-owners-core", Score: 100, Checks: []Check{repoCheck{}}}
+
+		got := stests.TestItems("core")
+
+		assertString(t, got.Results[0].Reality, repoUnresolved)
+		assertIDEquals(t, got.Score, 99)
+		if got.Status != NotReady {
+			t.Errorf("got status %q want %q", got.Status, NotReady)
+		}
+	})
+
+	t.Run("the owner check has nothing to compare without a repository", func(t *testing.T) {
+		stests := &SvcTestDB{Service: "core", Owner: "code-owners-core", Score: 100}
+
+		got := ownerCheck{}.TestItem(stests)
+
+		if got.Works || got.Reality != "" {
+			t.Errorf("got %+v want an unverified owner", got)
+		}
+	})
+}
@@ -0,0 +1,157 @@
+package main
+
+import (
+	"fmt"
+	"log/slog"
+	"sync"
+)
+
+// Principle is one of the Eight Principles of Production Readiness.
+// Any test performed by Verificat will fall into one or more of these.
+type Principle string
+
+const (
+	Stability               Principle = "stability"
+	Reliability             Principle = "reliability"
+	Scalability             Principle = "scalability"
+	Performance             Principle = "performance"
+	FaultTolerance          Principle = "fault-tolerance"
+	CatastrophePreparedness Principle = "catastrophe-preparedness"
+	Monitoring              Principle = "monitoring"
+	Documentation           Principle = "documentation"
+)
+
+// EightPrinciples lists every Principle in the order the README defines them.
+var EightPrinciples = []Principle{
+	Stability,
+	Reliability,
+	Scalability,
+	Performance,
+	FaultTolerance,
+	CatastrophePreparedness,
+	Monitoring,
+	Documentation,
+}
+
+// Readiness is the pass/fail gate for a service, independent of its Score.
+type Readiness string
+
+const (
+	Ready    Readiness = "Ready"    // Every Check passed, freshly deployable
+	Degraded Readiness = "Degraded" // Every Required Check passed, stays in Production with maintenance
+	NotReady Readiness = "NotReady" // At least one Required Check failed
+)
+
+// readinessOf grades a finished run.
+// A single failed Required Check makes a service NotReady no matter its Score.
+func readinessOf(results []*TestReturn) Readiness {
+	status := Ready
+	for _, tr := range results {
+		if tr.Works {
+			continue
+		}
+		if tr.Required {
+			return NotReady
+		}
+		status = Degraded
+	}
+	return status
+}
+
+// Finding is one specific problem a Check found, pointing at where it is.
+type Finding struct {
+	Path    string `json:",omitempty"` // The file (or field) with the problem
+	Line    int    `json:",omitempty"` // The line in that file, if there is one
+	Message string
+}
+
+// PrincipleScores breaks a Score down by Principle.
+// Each Principle a run touches starts at 100 like the overall Score,
+// and loses the points of every failed Check that covers it.
+// Principles no Check covers are left out rather than shown as a perfect 100.
+type PrincipleScores map[Principle]int
+
+// deduct takes /points/ from every Principle in /ps/,
+// starting any Principle not seen yet at 100.
+func (p PrincipleScores) deduct(ps []Principle, points int) {
+	for _, principle := range ps {
+		if _, ok := p[principle]; !ok {
+			p[principle] = 100
+		}
+		p[principle] -= points
+	}
+}
+
+// CheckInfo is how a Check describes itself to the registry.
+type CheckInfo struct {
+	ID          string      // Short unique name, e.g.: owner
+	Description string      // What this check verifies
+	Principles  []Principle // Which of the Eight Principles this check covers
+	Required    bool        // Part of the Required Baseline, failing it makes a service NotReady
+}
+
+// Info lets a family of Checks sharing one TestItem embed their CheckInfo
+// instead of each writing its own Info().
+func (c CheckInfo) Info() CheckInfo {
+	return c
+}
+
+// Check is a richer version of SvcTest.
+// Each Production Readiness item is its own Check, living in its own file,
+// and registers itself with RegisterCheck from an init() function.
+// TestItem receives the shared SvcTestDB for the run,
+// so it can read what Backstage told us and adjust the Score.
+type Check interface {
+	Info() CheckInfo
+	TestItem(s *SvcTestDB) *TestReturn
+}
+
+// The check registry is filled at init time and read on every verification run.
+var (
+	checkMu       sync.RWMutex
+	checkRegistry []Check
+)
+
+// RegisterCheck adds a Check to the registry.
+// Checks run in the order they are registered.
+// Registering an empty or duplicate ID is a programming error and panics.
+func RegisterCheck(c Check) {
+	checkMu.Lock()
+	defer checkMu.Unlock()
+
+	info := c.Info()
+	if info.ID == "" {
+		panic("verificat: check registered without an ID")
+	}
+	for _, existing := range checkRegistry {
+		if existing.Info().ID == info.ID {
+			panic(fmt.Sprintf("verificat: check %q registered twice", info.ID))
+		}
+	}
+
+	checkRegistry = append(checkRegistry, c)
+	slog.Debug("Check Registered", slog.String("ID", info.ID), slog.Any("Principles", info.Principles))
+}
+
+// RegisteredChecks returns a copy of the registry in registration order.
+func RegisteredChecks() []Check {
+	checkMu.RLock()
+	defer checkMu.RUnlock()
+
+	checks := make([]Check, len(checkRegistry))
+	copy(checks, checkRegistry)
+	return checks
+}
+
+// LookupCheck finds a registered Check by its ID.
+func LookupCheck(id string) (Check, bool) {
+	checkMu.RLock()
+	defer checkMu.RUnlock()
+
+	for _, c := range checkRegistry {
+		if c.Info().ID == id {
+			return c, true
+		}
+	}
+	return nil, false
+}
@@ -0,0 +1,165 @@
+package main
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+// Mock check that always fails and costs a single point
+type mockFailCheck struct {
+	id       string
+	required bool
+}
+
+func (m mockFailCheck) Info() CheckInfo {
+	return CheckInfo{ID: m.id, Description: "always fails", Principles: []Principle{Stability}, Required: m.required}
+}
+
+func (m mockFailCheck) TestItem(s *SvcTestDB) *TestReturn {
+	s.Score--
+	return &TestReturn{Present: true, Works: false, Score: s.Score}
+}
+
+// Mock check that always passes
+type mockPassCheck struct {
+	id string
+}
+
+func (m mockPassCheck) Info() CheckInfo {
+	return CheckInfo{ID: m.id, Description: "always passes", Principles: []Principle{Monitoring, Documentation}}
+}
+
+func (m mockPassCheck) TestItem(s *SvcTestDB) *TestReturn {
+	return &TestReturn{Present: true, Works: true, Score: s.Score}
+}
+
+func assertScore(t testing.TB, got, want int) {
+	t.Helper()
+	if got != want {
+		t.Errorf("got score %d want %d", got, want)
+	}
+}
+
+// assertCharged checks a Check run from a Score of 100 took a point if, and only if, it was expected to fail.
+func assertCharged(t testing.TB, s *SvcTestDB, works bool) {
+	t.Helper()
+	want := 100
+	if !works {
+		want = 99
+	}
+	assertScore(t, s.Score, want)
+}
+
+func TestCheckRegistry(t *testing.T) {
+	t.Run("the owner check registers itself", func(t *testing.T) {
+		c, ok := LookupCheck("owner")
+		if !ok {
+			t.Fatalf("owner check was not found in the registry")
+		}
+		assertString(t, c.Info().ID, "owner")
+	})
+
+	t.Run("registered checks are returned as a copy", func(t *testing.T) {
+		checks := RegisteredChecks()
+		checks[0] = mockPassCheck{"overwritten"}
+
+		if _, ok := LookupCheck("overwritten"); ok {
+			t.Errorf("changing the returned slice changed the registry")
+		}
+	})
+
+	t.Run("registering a duplicate ID panics", func(t *testing.T) {
+		defer func() {
+			if r := recover(); r == nil {
+				t.Errorf("expected a panic for a duplicate check ID")
+			}
+		}()
+		RegisterCheck(ownerCheck{})
+	})
+
+	t.Run("registering an empty ID panics", func(t *testing.T) {
+		defer func() {
+			if r := recover(); r == nil {
+				t.Errorf("expected a panic for an empty check ID")
+			}
+		}()
+		RegisterCheck(mockPassCheck{""})
+	})
+}
+
+// Every Check runs in order against the same SvcTestDB
+func TestTestItems(t *testing.T) {
+	stests := &SvcTestDB{
+		Datetime: 1724367242,
+		Owner:    "code-owners-admin",
+		Score:    100,
+		Checks:   []Check{mockFailCheck{"first", false}, mockPassCheck{"second"}, mockFailCheck{"third", false}},
+	}
+
+	got := stests.TestItems("admin")
+	want := &VerifyResult{
+		Service:  "admin",
+		Datetime: 1724367242,
+		Score:    98,
+		Status:   Degraded,
+		Principles: PrincipleScores{
+			Stability:     98,
+			Monitoring:    100,
+			Documentation: 100,
+		},
+		Results: []*TestReturn{
+			{ID: "first", Present: true, Works: false, Score: 99, Principles: []Principle{Stability}, Penalty: 1},
+			{ID: "second", Present: true, Works: true, Score: 99, Principles: []Principle{Monitoring, Documentation}},
+			{ID: "third", Present: true, Works: false, Score: 98, Principles: []Principle{Stability}, Penalty: 1},
+		},
+	}
+
+	if diff := cmp.Diff(got, want); diff != "" {
+		t.Error(diff)
+	}
+}
+
+// A Principle only loses the points of Checks that cover it
+func TestPrincipleScores(t *testing.T) {
+	got := PrincipleScores{}
+	got.deduct([]Principle{Stability, Monitoring}, 2)
+	got.deduct([]Principle{Monitoring}, 1)
+	got.deduct([]Principle{Documentation}, 0)
+
+	want := PrincipleScores{
+		Stability:     98,
+		Monitoring:    97,
+		Documentation: 100,
+	}
+
+	if diff := cmp.Diff(got, want); diff != "" {
+		t.Error(diff)
+	}
+}
+
+// Readiness is graded on pass/fail, never on the Score
+func TestReadiness(t *testing.T) {
+	readinessTests := []struct {
+		Name   string
+		Checks []Check
+		Expect Readiness
+	}{
+		{"Everything passes", []Check{mockPassCheck{"a"}, mockPassCheck{"b"}}, Ready},
+		{"Optional failure", []Check{mockPassCheck{"a"}, mockFailCheck{"b", false}}, Degraded},
+		{"Required failure", []Check{mockPassCheck{"a"}, mockFailCheck{"b", true}}, NotReady},
+		{"Required beats optional", []Check{mockFailCheck{"a", false}, mockFailCheck{"b", true}}, NotReady},
+	}
+
+	for _, tt := range readinessTests {
+		t.Run(tt.Name, func(t *testing.T) {
+			// A high Score does not save a service from a failed Required Check
+			stests := &SvcTestDB{Score: 100, Checks: tt.Checks}
+			got := stests.TestItems("admin").Status
+
+			if got != tt.Expect {
+				t.Errorf("got status %q want %q", got, tt.Expect)
+			}
+		})
+	}
+}
@@ -0,0 +1,284 @@
+package main
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"regexp"
+	"strings"
+)
+
+// OwnerKind is what sort of handle a CODEOWNERS owner is.
+type OwnerKind string
+
+const (
+	OwnerUser  OwnerKind = "user"  // @username
+	OwnerTeam  OwnerKind = "team"  // @org/team-slug
+	OwnerEmail OwnerKind = "email" // user@example.com
+)
+
+var (
+	userHandle  = regexp.MustCompile(`^@[A-Za-z0-9][A-Za-z0-9-]*$`)
+	teamHandle  = regexp.MustCompile(`^@[A-Za-z0-9][A-Za-z0-9-]*/[A-Za-z0-9][A-Za-z0-9._-]*$`)
+	emailHandle = regexp.MustCompile(`^[^@\s]+@[^@\s]+\.[^@\s]+$`)
+)
+
+// CodeOwner is one owner named on a CODEOWNERS rule.
+type CodeOwner struct {
+	Handle string    // As written, e.g.: @GhostGroup/js-developers
+	Kind   OwnerKind // user, team or email
+}
+
+// Name is the owner without its CODEOWNERS decoration:
+// the team slug, the username, or the email address.
+func (o CodeOwner) Name() string {
+	switch o.Kind {
+	case OwnerTeam:
+		return o.Handle[strings.Index(o.Handle, "/")+1:]
+	case OwnerUser:
+		return strings.TrimPrefix(o.Handle, "@")
+	}
+	return o.Handle
+}
+
+// CodeownersRule is one line of a CODEOWNERS file: a path pattern and who owns it.
+// A rule with no owners is valid, it leaves the matching paths unowned.
+type CodeownersRule struct {
+	Line    int
+	Pattern string
+	Owners  []CodeOwner
+	match   *regexp.Regexp
+}
+
+// CodeownersError is a line GitHub would skip.
+type CodeownersError struct {
+	Line   int
+	Reason string
+}
+
+func (e CodeownersError) Error() string {
+	return fmt.Sprintf("line %d: %s", e.Line, e.Reason)
+}
+
+// Codeowners is a parsed CODEOWNERS file.
+// Lines with errors are left out of Rules, the same as GitHub ignores them.
+type Codeowners struct {
+	Rules  []CodeownersRule
+	Errors []CodeownersError
+}
+
+// ParseCodeowners reads a CODEOWNERS file.
+// Only a failure to read is returned as an error,
+// syntax problems are collected in Codeowners.Errors so the valid rules can still be used.
+func ParseCodeowners(r io.Reader) (*Codeowners, error) {
+	co := new(Codeowners)
+
+	scanner := bufio.NewScanner(r)
+	for line := 1; scanner.Scan(); line++ {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) == 0 || strings.HasPrefix(fields[0], "#") {
+			continue
+		}
+
+		rule, err := parseCodeownersRule(line, fields)
+		if err != nil {
+			co.Errors = append(co.Errors, CodeownersError{Line: line, Reason: err.Error()})
+			continue
+		}
+		co.Rules = append(co.Rules, rule)
+	}
+
+	return co, scanner.Err()
+}
+
+// parseCodeownersRule reads the pattern and owners of one non-comment line.
+func parseCodeownersRule(line int, fields []string) (CodeownersRule, error) {
+	// A pattern starting with # is escaped as \#
+	pattern := strings.Replace(fields[0], `\#`, "#", 1)
+
+	match, err := codeownersPattern(pattern)
+	if err != nil {
+		return CodeownersRule{}, err
+	}
+	rule := CodeownersRule{Line: line, Pattern: pattern, match: match}
+
+	for _, handle := range fields[1:] {
+		// The rest of the line is a comment
+		if strings.HasPrefix(handle, "#") {
+			break
+		}
+
+		switch {
+		case teamHandle.MatchString(handle):
+			rule.Owners = append(rule.Owners, CodeOwner{Handle: handle, Kind: OwnerTeam})
+		case userHandle.MatchString(handle):
+			rule.Owners = append(rule.Owners, CodeOwner{Handle: handle, Kind: OwnerUser})
+		case emailHandle.MatchString(handle):
+			rule.Owners = append(rule.Owners, CodeOwner{Handle: handle, Kind: OwnerEmail})
+		default:
+			return CodeownersRule{}, fmt.Errorf("%q is not a user, team or email", handle)
+		}
+	}
+
+	return rule, nil
+}
+
+// codeownersPattern turns a CODEOWNERS path pattern into a regexp over repository paths.
+// This is gitignore syntax without negation or character ranges, which CODEOWNERS doesn't support:
+// a leading or inner / anchors the pattern to the root, a trailing / only matches directories,
+// * and ? stay within a directory and ** crosses them.
+func codeownersPattern(pattern string) (*regexp.Regexp, error) {
+	if strings.HasPrefix(pattern, "!") {
+		return nil, errors.New("negation patterns (!) are not supported")
+	}
+	if strings.ContainsAny(pattern, "[]") {
+		return nil, errors.New("character ranges ([ ]) are not supported")
+	}
+
+	dirOnly := strings.HasSuffix(pattern, "/")
+	p := strings.TrimSuffix(pattern, "/")
+	anchored := strings.Contains(p, "/")
+	p = strings.TrimPrefix(p, "/")
+	if p == "" {
+		return nil, errors.New("empty pattern")
+	}
+
+	var expr strings.Builder
+	expr.WriteString("^")
+	if !anchored {
+		expr.WriteString("(.*/)?")
+	}
+	for i := 0; i < len(p); i++ {
+		switch {
+		case strings.HasPrefix(p[i:], "**/"):
+			expr.WriteString("(.*/)?")
+			i += 2
+		case strings.HasPrefix(p[i:], "**"):
+			expr.WriteString(".*")
+			i++
+		case p[i] == '*':
+			expr.WriteString("[^/]*")
+		case p[i] == '?':
+			expr.WriteString("[^/]")
+		default:
+			expr.WriteString(regexp.QuoteMeta(p[i : i+1]))
+		}
+	}
+	// Matching a directory matches everything beneath it,
+	// except a trailing * which GitHub keeps to that one directory, e.g.: docs/* doesn't own docs/build/x.md
+	switch {
+	case dirOnly:
+		expr.WriteString("/.*$")
+	case p == "*" || strings.HasSuffix(p, "/*") && !strings.HasSuffix(p, "**/*"):
+		expr.WriteString("$")
+	default:
+		expr.WriteString("(/.*)?$")
+	}
+
+	return regexp.Compile(expr.String())
+}
+
+// OwnersOf returns who owns a file, given its path from the repository root.
+// The last matching rule wins, the same as GitHub.
+func (co *Codeowners) OwnersOf(path string) []CodeOwner {
+	path = strings.TrimPrefix(path, "/")
+	for i := len(co.Rules) - 1; i >= 0; i-- {
+		if co.Rules[i].match.MatchString(path) {
+			return co.Rules[i].Owners
+		}
+	}
+	return nil
+}
+
+// rootProbes are paths no rule names on purpose: a file at the root and one beneath a directory.
+// A rule matching both of them covers every path.
+var rootProbes = []string{"root-owner-probe", "root-owner-probe/root-owner-probe"}
+
+// RootOwners returns who owns the repository as a whole:
+// the last rule whose pattern covers every path, e.g. "*" or "**/*".
+// Path-specific rules (docs/, *.go) don't change who owns the root.
+func (co *Codeowners) RootOwners() []CodeOwner {
+	for i := len(co.Rules) - 1; i >= 0; i-- {
+		covers := true
+		for _, probe := range rootProbes {
+			covers = covers && co.Rules[i].match.MatchString(probe)
+		}
+		if covers {
+			return co.Rules[i].Owners
+		}
+	}
+	return nil
+}
+
+// ownerMatches is true when the Backstage owner is one of the CODEOWNERS owners.
+// Teams are compared by slug, so the GitHub organization doesn't matter.
+func ownerMatches(backstageOwner string, owners []CodeOwner) bool {
+	name := entityRefName(backstageOwner)
+	for _, o := range owners {
+		if strings.EqualFold(name, o.Name()) {
+			return true
+		}
+	}
+	return false
+}
+
+// ownerNames lists the owners the way they're reported in a TestReturn.
+func ownerNames(owners []CodeOwner) string {
+	names := make([]string, len(owners))
+	for i, o := range owners {
+		names[i] = o.Name()
+	}
+	return strings.Join(names, " ")
+}
+
+// codeownersPaths are where GitHub looks for CODEOWNERS, in its order of precedence.
+// The first one found is the only one GitHub uses.
+var codeownersPaths = []string{".github/CODEOWNERS", "CODEOWNERS", "docs/CODEOWNERS"}
+
+var CodeownersMissing = errors.New("CODEOWNERS not found in .github/, the repository root, or docs/")
+
+// codeownersRead is a CODEOWNERS file fetched once per run,
+// shared by every Check that needs it.
+type codeownersRead struct {
+	path string
+	file *Codeowners
+	err  error
+}
+
+var errRepoUnresolved = errors.New(repoUnresolved)
+
+// readCodeowners fetches and parses the Service's CODEOWNERS file the first time it's asked for.
+// It returns the path the file was found at, or CodeownersMissing if there isn't one.
+func (s *SvcTestDB) readCodeowners() (*Codeowners, string, error) {
+	if s.codeowners == nil {
+		s.codeowners = fetchCodeowners(s.github(), s.Repo)
+	}
+	return s.codeowners.file, s.codeowners.path, s.codeowners.err
+}
+
+// fetchCodeowners probes each of the codeownersPaths in /repo/ and parses the first one found.
+func fetchCodeowners(gh *GitHubClient, repo string) *codeownersRead {
+	if repo == "" {
+		return &codeownersRead{err: errRepoUnresolved}
+	}
+
+	for _, path := range codeownersPaths {
+		answer, err := gh.Get(urlCat(gh.BaseURL, ghPreURI, repo, ghContentsPATH, path))
+		if errors.Is(err, GitHubNotFound) {
+			continue
+		}
+		if err != nil {
+			slog.Error("Cannot Fetch", slog.Any("Error", err))
+			return &codeownersRead{path: path, err: err}
+		}
+
+		slog.Info("CODEOWNERS Found", slog.String("Repo", repo), slog.String("Path", path))
+		file, err := ParseCodeowners(strings.NewReader(answer))
+		return &codeownersRead{path: path, file: file, err: err}
+	}
+
+	slog.Warn("CODEOWNERS Missing", slog.String("Repo", repo))
+	return &codeownersRead{err: CodeownersMissing}
+}
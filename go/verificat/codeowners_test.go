@@ -0,0 +1,257 @@
+package main
+
+import (
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+const testCodeowners = `# Everything belongs to the admin team
+*       @GhostGroup/code-owners-admin   @ghost-bot
+
+# Docs are looked after by the writers, or by email
+/docs/  @GhostGroup/tech-writers docs@weedmaps.com  # inline comment
+*.go    @gopher
+**/testdata/**
+\#notes @GhostGroup/note-takers
+
+# These are broken, GitHub skips them
+!vendor/ @GhostGroup/code-owners-admin
+*.js @GhostGroup/
+`
+
+func TestParseCodeowners(t *testing.T) {
+	co, err := ParseCodeowners(strings.NewReader(testCodeowners))
+	assertNoError(t, err)
+
+	t.Run("valid rules are kept in order", func(t *testing.T) {
+		var got []string
+		for _, r := range co.Rules {
+			got = append(got, r.Pattern)
+		}
+		want := []string{"*", "/docs/", "*.go", "**/testdata/**", "#notes"}
+		if diff := cmp.Diff(got, want); diff != "" {
+			t.Error(diff)
+		}
+	})
+
+	t.Run("owners are users, teams and emails", func(t *testing.T) {
+		got := co.Rules[1].Owners
+		want := []CodeOwner{
+			{Handle: "@GhostGroup/tech-writers", Kind: OwnerTeam},
+			{Handle: "docs@weedmaps.com", Kind: OwnerEmail},
+		}
+		if diff := cmp.Diff(got, want); diff != "" {
+			t.Error(diff)
+		}
+		assertString(t, co.Rules[0].Owners[1].Name(), "ghost-bot")
+		assertString(t, co.Rules[0].Owners[0].Name(), "code-owners-admin")
+	})
+
+	t.Run("broken lines are errors", func(t *testing.T) {
+		var got []int
+		for _, e := range co.Errors {
+			got = append(got, e.Line)
+		}
+		if diff := cmp.Diff(got, []int{11, 12}); diff != "" {
+			t.Error(diff)
+		}
+	})
+}
+
+// The last matching rule wins, the same as GitHub
+func TestCodeownersOwnersOf(t *testing.T) {
+	co, err := ParseCodeowners(strings.NewReader(testCodeowners))
+	assertNoError(t, err)
+
+	ownersTests := []struct {
+		Path   string
+		Expect string
+	}{
+		{"README.md", "code-owners-admin ghost-bot"},
+		{"docs/index.md", "tech-writers docs@weedmaps.com"},
+		{"docs/main.go", "gopher"},
+		{"src/docs/index.md", "code-owners-admin ghost-bot"},
+		{"cmd/server/main.go", "gopher"},
+		{"pkg/testdata/fixture.json", ""},
+		{"#notes", "note-takers"},
+	}
+
+	for _, tt := range ownersTests {
+		t.Run(tt.Path, func(t *testing.T) {
+			assertString(t, ownerNames(co.OwnersOf(tt.Path)), tt.Expect)
+		})
+	}
+
+	t.Run("root owners ignore path rules", func(t *testing.T) {
+		assertString(t, ownerNames(co.RootOwners()), "code-owners-admin ghost-bot")
+	})
+
+	t.Run("any pattern covering every path is a root rule", func(t *testing.T) {
+		for _, pattern := range []string{"*", "**", "/**", "**/*", "/**/*"} {
+			co, err := ParseCodeowners(strings.NewReader(pattern + " @GhostGroup/code-owners-admin\n*.go @gopher\n/docs/ @GhostGroup/tech-writers\n"))
+			assertNoError(t, err)
+			assertString(t, ownerNames(co.RootOwners()), "code-owners-admin")
+		}
+
+		// Only the files at the root, not what's beneath its directories
+		co, err := ParseCodeowners(strings.NewReader("/* @GhostGroup/code-owners-admin\n"))
+		assertNoError(t, err)
+		assertString(t, ownerNames(co.OwnersOf("README.md")), "code-owners-admin")
+		assertString(t, ownerNames(co.OwnersOf("docs/index.md")), "")
+		if got := co.RootOwners(); got != nil {
+			t.Errorf("got %v want /* left out of the root owners", got)
+		}
+	})
+
+	t.Run("a trailing * stays in its directory", func(t *testing.T) {
+		co, err := ParseCodeowners(strings.NewReader("* @GhostGroup/admin\ndocs/* @GhostGroup/writers\n"))
+		assertNoError(t, err)
+		assertString(t, ownerNames(co.OwnersOf("docs/index.md")), "writers")
+		assertString(t, ownerNames(co.OwnersOf("docs/build/x.md")), "admin")
+	})
+
+	t.Run("no root rule, no root owners", func(t *testing.T) {
+		co, _ := ParseCodeowners(strings.NewReader("/docs/ @GhostGroup/tech-writers\n"))
+		if got := co.RootOwners(); got != nil {
+			t.Errorf("got %v want no root owners", got)
+		}
+	})
+}
+
+// Backstage entity references compare against CODEOWNERS handles
+func TestOwnerMatches(t *testing.T) {
+	owners := []CodeOwner{{Handle: "@OtherOrg/Code-Owners-Admin", Kind: OwnerTeam}, {Handle: "@jdoe", Kind: OwnerUser}}
+
+	matchTests := []struct {
+		Owner  string
+		Expect bool
+	}{
+		{"code-owners-admin", true},
+		{"group:default/code-owners-admin", true},
+		{"group:code-owners-admin", true},
+		{"user:default/jdoe", true},
+		{"js-developers", false},
+		{"", false},
+	}
+
+	for _, tt := range matchTests {
+		t.Run(tt.Owner, func(t *testing.T) {
+			if got := ownerMatches(tt.Owner, owners); got != tt.Expect {
+				t.Errorf("got %v want %v", got, tt.Expect)
+			}
+		})
+	}
+}
+
+// withCodeowners gives a SvcTestDB a CODEOWNERS file as if it was found at .github/CODEOWNERS.
+func withCodeowners(t testing.TB, s *SvcTestDB, file string) *SvcTestDB {
+	t.Helper()
+	co, err := ParseCodeowners(strings.NewReader(file))
+	assertNoError(t, err)
+	s.codeowners = &codeownersRead{path: ".github/CODEOWNERS", file: co}
+	return s
+}
+
+// withoutCodeowners gives a SvcTestDB a repository with no CODEOWNERS anywhere.
+func withoutCodeowners(s *SvcTestDB) *SvcTestDB {
+	s.codeowners = &codeownersRead{err: CodeownersMissing}
+	return s
+}
+
+func TestCodeownersChecks(t *testing.T) {
+	t.Run("the owner matches a multi-line file", func(t *testing.T) {
+		stests := withCodeowners(t, &SvcTestDB{Owner: "group:default/code-owners-admin", Repo: "GhostGroup/admin", Score: 100}, testCodeowners)
+
+		got := ownerCheck{}.TestItem(stests)
+		want := &TestReturn{Present: true, Owner: "group:default/code-owners-admin", Reality: "code-owners-admin ghost-bot", Source: ".github/CODEOWNERS", Works: true, Score: 100}
+		if diff := cmp.Diff(got, want); diff != "" {
+			t.Error(diff)
+		}
+	})
+
+	t.Run("a different root owner is a mismatch", func(t *testing.T) {
+		stests := withCodeowners(t, &SvcTestDB{Owner: "code-owners-admin", Repo: "GhostGroup/admin", Score: 100}, "* @GhostGroup/js-developers\n")
+
+		got := ownerCheck{}.TestItem(stests)
+		want := &TestReturn{Present: true, Owner: "code-owners-admin", Reality: "js-developers", Source: ".github/CODEOWNERS", Works: false, Score: 99, Findings: []Finding{
+			{Path: ".github/CODEOWNERS", Message: `owner "code-owners-admin" is not a root owner in CODEOWNERS (js-developers)`},
+		}}
+		if diff := cmp.Diff(got, want); diff != "" {
+			t.Error(diff)
+		}
+	})
+
+	t.Run("a missing file is not a mismatch", func(t *testing.T) {
+		stests := withoutCodeowners(&SvcTestDB{Owner: "code-owners-admin", Repo: "GhostGroup/admin", Score: 100})
+
+		// The codeowners Check carries the point, the owner just can't be verified
+		got := ownerCheck{}.TestItem(stests)
+		want := &TestReturn{Present: true, Owner: "code-owners-admin", Works: false, Score: 100}
+		if diff := cmp.Diff(got, want); diff != "" {
+			t.Error(diff)
+		}
+
+		got = codeownersCheck{}.TestItem(stests)
+		want = &TestReturn{Present: false, Owner: "code-owners-admin", Reality: CodeownersMissing.Error(), Works: false, Score: 99, Findings: []Finding{
+			{Path: ".github/CODEOWNERS", Message: "not found"},
+			{Path: "CODEOWNERS", Message: "not found"},
+			{Path: "docs/CODEOWNERS", Message: "not found"},
+		}}
+		if diff := cmp.Diff(got, want); diff != "" {
+			t.Error(diff)
+		}
+	})
+
+	t.Run("parse errors are their own finding", func(t *testing.T) {
+		stests := withCodeowners(t, &SvcTestDB{Owner: "code-owners-admin", Repo: "GhostGroup/admin", Score: 100}, testCodeowners)
+
+		got := codeownersCheck{}.TestItem(stests)
+		want := &TestReturn{Present: true, Owner: "code-owners-admin", Reality: ".github/CODEOWNERS", Source: ".github/CODEOWNERS", Works: false, Score: 99, Findings: []Finding{
+			{Path: ".github/CODEOWNERS", Line: 11, Message: "negation patterns (!) are not supported"},
+			{Path: ".github/CODEOWNERS", Line: 12, Message: `"@GhostGroup/" is not a user, team or email`},
+		}}
+		if diff := cmp.Diff(got, want); diff != "" {
+			t.Error(diff)
+		}
+	})
+
+	t.Run("a clean file passes", func(t *testing.T) {
+		stests := withCodeowners(t, &SvcTestDB{Owner: "code-owners-admin", Repo: "GhostGroup/admin", Score: 100}, "* @GhostGroup/code-owners-admin\n")
+
+		got := codeownersCheck{}.TestItem(stests)
+		if !got.Works || got.Score != 100 {
+			t.Errorf("got %+v want a pass", got)
+		}
+	})
+
+	t.Run("GitHub failing to answer costs nothing and is the outage", func(t *testing.T) {
+		gh, _, _ := newTestGitHubClient(t, answer(http.StatusServiceUnavailable, ""))
+		s := &SvcTestDB{Owner: "code-owners-admin", Repo: "GhostGroup/admin", GitHub: gh, Score: 100}
+
+		for _, c := range []Check{codeownersCheck{}, ownerCheck{}} {
+			got := c.TestItem(s)
+			if got.Works || len(got.Findings) != 0 || s.Score != 100 {
+				t.Errorf("got %+v want a failure that costs nothing", got)
+			}
+		}
+		assertError(t, s.Outage(), errGitHubTransient)
+	})
+
+	t.Run("no repository, nothing to read", func(t *testing.T) {
+		// The repository Check already reports this and carries the point
+		s := &SvcTestDB{Score: 100}
+		got := codeownersCheck{}.TestItem(s)
+		if !got.Works || got.Reality != repoUnresolved || s.Score != 100 {
+			t.Errorf("got %+v want CODEOWNERS left to the repository Check", got)
+		}
+
+		s = &SvcTestDB{Owner: "code-owners-admin", Score: 100}
+		got = ownerCheck{}.TestItem(s)
+		if got.Works || len(got.Findings) != 0 || s.Score != 100 {
+			t.Errorf("got %+v want an unverified owner with no findings", got)
+		}
+	})
+}
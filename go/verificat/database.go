@@ -4,15 +4,22 @@ import (
 	"encoding/json"
 	"fmt"
 	"io"
+	"log/slog"
 	"os"
 	"sort"
+	"sync"
+	"time"
 )
 
 // FSStore uses a *json.Encoder here
 // because we're performing a lot of file ops in the constructor.
+// Every run is kept in /history/, the /almanac/ holds only the latest of each.
+// HTTP requests are served concurrently, so /mu/ guards all of it.
 type FSStore struct {
+	mu       sync.RWMutex
 	database *json.Encoder
 	almanac  Almanac
+	history  History
 }
 
 // NewFSStore Constructor
@@ -23,17 +30,18 @@ func NewFSStore(file *os.File) (*FSStore, error) {
 		return nil, fmt.Errorf("problem initialising service db file, %v", err)
 	}
 
-	// create a new Almanac object
-	almanac, err := NewAlmanac(file)
+	// create new Almanac and History objects
+	almanac, history, err := NewDatabase(file)
 	if err != nil {
 		return nil, fmt.Errorf("problem loading service store from file %s, %v", file.Name(), err)
 	}
 
-	// using the /tape/ type to encapsulate the database
-	// allows us to always start at the beginning of the file
+	// using the /atomicFile/ type to encapsulate the database
+	// replaces the whole file on every write, so a crash never truncates it
 	return &FSStore{
-		database: json.NewEncoder(&tape{file}),
+		database: json.NewEncoder(&atomicFile{file.Name()}),
 		almanac:  almanac,
+		history:  history,
 	}, nil
 }
 
@@ -59,16 +67,24 @@ func initDBFile(file *os.File) error {
 }
 
 // GetAlmanac provides a sorted list of all services and their LastID.
+// The list is a copy, so callers can't change the store out from under it.
 func (f *FSStore) GetAlmanac() Almanac {
-	sort.Slice(f.almanac, func(i, j int) bool {
-		return f.almanac[i].LastID > f.almanac[j].LastID
+	f.mu.RLock()
+	almanac := f.almanac.Copy()
+	f.mu.RUnlock()
+
+	sort.Slice(almanac, func(i, j int) bool {
+		return almanac[i].LastID > almanac[j].LastID
 	})
-	return f.almanac
+	return almanac
 }
 
 // GetTriggerID does a lookup for the LastID for a given name.
 // The var /service/ is a WMService
 func (f *FSStore) GetTriggerID(name string) int {
+	f.mu.RLock()
+	defer f.mu.RUnlock()
+
 	service := f.almanac.Find(name)
 
 	if service != nil {
@@ -80,8 +96,12 @@ func (f *FSStore) GetTriggerID(name string) int {
 
 // TriggerID increases LastID by one, providing a run count.
 // If it's a new service, create them and start their tally at 1.
+// The run itself is appended to the History under that new LastID.
 // The var /service/ is a WMService
-func (f *FSStore) TriggerID(name string, score int) {
+func (f *FSStore) TriggerID(name string, result *VerifyResult) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
 	service := f.almanac.Find(name)
 
 	// TriggerID needs to set the Score, it is the "trigger" for things happening.
@@ -90,20 +110,55 @@ func (f *FSStore) TriggerID(name string, score int) {
 	// If not, add them to this almanac with an initial LastID.
 	if service != nil {
 		service.LastID++
-		service.Score = score
+		service.Kind = result.Kind
+		service.System = result.System
+		service.Score = result.Score
+		service.Status = result.Status
+		service.Principles = result.Principles
 	} else {
-		// Initialize the service in the almanac
-		f.almanac = append(f.almanac, WMService{name, 1, 100})
+		// Initialize the service in the almanac with this first result
+		f.almanac = append(f.almanac, WMService{Name: name, Kind: result.Kind, System: result.System, LastID: 1, Score: result.Score, Status: result.Status, Principles: result.Principles})
+		service = &f.almanac[len(f.almanac)-1]
+	}
+
+	// Keep this run as its own record, and let the caller know its run ID
+	f.history = append(f.history, newRunRecord(name, service.LastID, result))
+	result.RunID = service.LastID
+
+	// This seek call isn't needed here because we're replacing the whole file
+	// for writing thanks to the /atomicFile/ type
+	if err := f.database.Encode(fsDatabase{Almanac: f.almanac, History: f.history}); err != nil {
+		slog.Error("Failed to write database", slog.String("Service", name), slog.Any("Error", err))
 	}
+}
+
+// GetHistory returns every stored run for a service inside a time range.
+// A zero /from/ or /to/ leaves that end of the range open.
+func (f *FSStore) GetHistory(name string, from, to time.Time) []VerifyResult {
+	f.mu.RLock()
+	defer f.mu.RUnlock()
+
+	return f.history.Between(name, from, to)
+}
 
-	// This seek call isn't needed here because we're seeking to the beginning
-	// for writing thanks to the /tape/ type
-	f.database.Encode(f.almanac)
+// newRunRecord stamps a finished result with its run ID,
+// falling back to the current time if the run didn't record when it started.
+func newRunRecord(name string, runID int, result *VerifyResult) VerifyResult {
+	record := *result
+	record.Service = name
+	record.RunID = runID
+	if record.Datetime == 0 {
+		record.Datetime = time.Now().Unix()
+	}
+	return record
 }
 
 // GetScore is a lookup for the Score for a given name.
 // The var /service/ is a WMService
 func (f *FSStore) GetScore(name string) int {
+	f.mu.RLock()
+	defer f.mu.RUnlock()
+
 	service := f.almanac.Find(name)
 
 	if service != nil {
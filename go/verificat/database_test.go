@@ -1,9 +1,14 @@
 package main
 
 import (
-	"log"
+	"fmt"
 	"os"
+	"reflect"
+	"sync"
 	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
 )
 
 // By returning a function that closes the file,
@@ -29,34 +34,84 @@ func createTempFile(t testing.TB, initialData string) (*os.File, func()) {
 	return tmpfile, removeFile
 }
 
+// suiteStore is everything the store test suite exercises.
+type suiteStore interface {
+	ServiceStore
+	GetScore(name string) int
+}
+
+// storeFactory builds a store seeded with a JSON almanac, cleaned up with the test.
+// The returned /reopen/ builds a second store on the same database,
+// which shows what was actually persisted.
+type storeFactory func(t *testing.T, seed string) (store suiteStore, reopen func() suiteStore)
+
 func TestFileSystemStore(t *testing.T) {
+	testServiceStore(t, newTestFSStore)
 
-	// Test that an almanac is returned from a database call
-	t.Run("almanac from a reader", func(t *testing.T) {
-		database, cleanDatabase := createTempFile(t, `[
-			{"Name": "Mattic", "LastID": 10, "Score": 99},
-			{"Name": "Craque", "LastID": 33, "Score": 98}]`)
+	t.Run("works with an empty file", func(t *testing.T) {
+		// "" creates an existing but empty file
+		database, cleanDatabase := createTempFile(t, "")
 		defer cleanDatabase()
 
-		store, err := NewFSStore(database)
-		if err != nil {
-			log.Fatalf("Almanac from reader: problem creating file system service store, %v ", err)
+		_, err := NewFSStore(database)
+
+		assertNoError(t, err)
+	})
+
+	t.Run("legacy almanac files have no History", func(t *testing.T) {
+		store, _ := newTestFSStore(t, `[
+			{"Name": "Mattic", "LastID": 10, "Score": 99}]`)
+
+		if got := store.GetHistory("Mattic", time.Time{}, time.Time{}); len(got) != 0 {
+			t.Errorf("got %v want no History", got)
 		}
+	})
+}
+
+// newTestFSStore seeds a temp JSON database file.
+func newTestFSStore(t *testing.T, seed string) (suiteStore, func() suiteStore) {
+	t.Helper()
 
+	database, cleanDatabase := createTempFile(t, seed)
+	t.Cleanup(cleanDatabase)
+
+	store, err := NewFSStore(database)
+	assertNoError(t, err)
+
+	// Writes replace the file, so read back whatever is at its path now
+	reopen := func() suiteStore {
+		reopened, err := os.Open(database.Name())
+		assertNoError(t, err)
+		t.Cleanup(func() { reopened.Close() })
+
+		reloaded, err := NewFSStore(reopened)
 		assertNoError(t, err)
+		return reloaded
+	}
+
+	return store, reopen
+}
+
+// testServiceStore is the test suite every ServiceStore must pass.
+func testServiceStore(t *testing.T, newStore storeFactory) {
+	// Test that an almanac is returned from a database call
+	t.Run("almanac from a reader", func(t *testing.T) {
+		store, _ := newStore(t, `[
+			{"Name": "Mattic", "LastID": 10, "Score": 99},
+			{"Name": "Craque", "LastID": 33, "Score": 98}]`)
 
 		got := store.GetAlmanac()
 
 		// This comes back sorted!
 		want := []WMService{
-			{"Craque", 33, 98},
-			{"Mattic", 10, 99},
+			{Name: "Craque", LastID: 33, Score: 98},
+			{Name: "Mattic", LastID: 10, Score: 99},
 		}
 
 		// Almanac returns Name, ID, Score
 		assertAlmanac(t, got, want)
 
-		// read again to test tape rewind
+		// read again, the store hands back a fresh copy each time
 		got = store.GetAlmanac()
 
 		assertAlmanac(t, got, want)
@@ -64,17 +119,9 @@ func TestFileSystemStore(t *testing.T) {
 
 	// Get a service LastID from the almanac database
 	t.Run("get service LastID", func(t *testing.T) {
-		database, cleanDatabase := createTempFile(t, `[
+		store, _ := newStore(t, `[
 			{"Name": "Mattic", "LastID": 10, "Score": 99},
 			{"Name": "Craque", "LastID": 33, "Score": 98}]`)
-		defer cleanDatabase()
-
-		store, err := NewFSStore(database)
-		if err != nil {
-			log.Fatalf("Get LastID: problem creating file system service store, %v ", err)
-		}
-
-		assertNoError(t, err)
 
 		got := store.GetTriggerID("Craque")
 		want := 33
@@ -83,21 +130,12 @@ func TestFileSystemStore(t *testing.T) {
 
 	// This now needs to take WMService.Score
 	t.Run("store LastID for existing services", func(t *testing.T) {
-		database, cleanDatabase := createTempFile(t, `[
+		store, _ := newStore(t, `[
 			{"Name": "Mattic", "LastID": 10, "Score": 99},
 			{"Name": "Craque", "LastID": 33, "Score": 98}]`)
-		defer cleanDatabase()
-
-		//store := FSStore{database}
-		store, err := NewFSStore(database)
-		if err != nil {
-			log.Fatalf("Store LastID: problem creating file system service store, %v ", err)
-		}
-
-		assertNoError(t, err)
 
 		craqueScore := 99
-		store.TriggerID("Craque", craqueScore)
+		store.TriggerID("Craque", &VerifyResult{Score: craqueScore})
 
 		got := store.GetTriggerID("Craque")
 		want := 34
@@ -105,68 +143,140 @@ func TestFileSystemStore(t *testing.T) {
 	})
 
 	t.Run("store Score for existing services", func(t *testing.T) {
-		database, cleanDatabase := createTempFile(t, `[
+		store, _ := newStore(t, `[
 			{"Name": "Mattic", "LastID": 10, "Score": 99},
 			{"Name": "Craque", "LastID": 33, "Score": 98}]`)
-		defer cleanDatabase()
-
-		store, err := NewFSStore(database)
-		if err != nil {
-			log.Fatalf("Store LastID: problem creating file system service store, %v ", err)
-		}
-
-		assertNoError(t, err)
 
 		craqueScore := 99
-		store.TriggerID("Craque", craqueScore)
+		store.TriggerID("Craque", &VerifyResult{Score: craqueScore})
 
 		got := store.GetScore("Craque")
 		want := 99
 		assertIDEquals(t, got, want)
 	})
 
-	t.Run("store LastID for new services", func(t *testing.T) {
-		database, cleanDatabase := createTempFile(t, `[
+	t.Run("store Principles for existing services", func(t *testing.T) {
+		store, reopen := newStore(t, `[
 			{"Name": "Mattic", "LastID": 10, "Score": 99},
 			{"Name": "Craque", "LastID": 33, "Score": 98}]`)
-		defer cleanDatabase()
 
-		store, err := NewFSStore(database)
-		if err != nil {
-			log.Fatalf("New services: problem creating file system service store, %v ", err)
+		principles := PrincipleScores{Reliability: 99, CatastrophePreparedness: 99}
+		store.TriggerID("Craque", &VerifyResult{Score: 99, Principles: principles})
+
+		got := store.GetAlmanac().Find("Craque").Principles
+		if !reflect.DeepEqual(got, principles) {
+			t.Errorf("got principles %v want %v", got, principles)
 		}
 
-		store.TriggerID("Pepper", 0)
+		// The breakdown survives a reload from disk
+		got = reopen().GetAlmanac().Find("Craque").Principles
+		if !reflect.DeepEqual(got, principles) {
+			t.Errorf("got reloaded principles %v want %v", got, principles)
+		}
+	})
+
+	t.Run("store Status for existing services", func(t *testing.T) {
+		store, _ := newStore(t, `[
+			{"Name": "Mattic", "LastID": 10, "Score": 99},
+			{"Name": "Craque", "LastID": 33, "Score": 98}]`)
+
+		store.TriggerID("Craque", &VerifyResult{Score: 99, Status: NotReady})
+
+		got := store.GetAlmanac().Find("Craque").Status
+		if got != NotReady {
+			t.Errorf("got status %q want %q", got, NotReady)
+		}
+	})
+
+	t.Run("store LastID for new services", func(t *testing.T) {
+		store, _ := newStore(t, `[
+			{"Name": "Mattic", "LastID": 10, "Score": 99},
+			{"Name": "Craque", "LastID": 33, "Score": 98}]`)
+
+		store.TriggerID("Pepper", &VerifyResult{Score: 0})
 
 		got := store.GetTriggerID("Pepper")
 		want := 1
 		assertIDEquals(t, got, want)
 	})
 
-	t.Run("works with an empty file", func(t *testing.T) {
-		// "" creates an existing but empty file
-		database, cleanDatabase := createTempFile(t, "")
-		defer cleanDatabase()
+	t.Run("store every run in the History", func(t *testing.T) {
+		store, reopen := newStore(t, `[
+			{"Name": "Mattic", "LastID": 10, "Score": 99},
+			{"Name": "Craque", "LastID": 33, "Score": 98}]`)
 
-		_, err := NewFSStore(database)
+		store.TriggerID("Craque", &VerifyResult{Datetime: 1000, Score: 97})
+		store.TriggerID("Mattic", &VerifyResult{Datetime: 1500, Score: 96})
+		store.TriggerID("Craque", &VerifyResult{Datetime: 2000, Score: 99, Results: []*TestReturn{{ID: "owner", Works: true}}})
 
-		assertNoError(t, err)
+		got := store.GetHistory("Craque", time.Time{}, time.Time{})
+		want := []VerifyResult{
+			{Service: "Craque", RunID: 34, Datetime: 1000, Score: 97},
+			{Service: "Craque", RunID: 35, Datetime: 2000, Score: 99, Results: []*TestReturn{{ID: "owner", Works: true}}},
+		}
+		if diff := cmp.Diff(got, want); diff != "" {
+			t.Error(diff)
+		}
+
+		// Only the latest run is in the almanac
+		assertIDEquals(t, store.GetScore("Craque"), 99)
+
+		// A time range narrows the History
+		got = store.GetHistory("Craque", time.Unix(1500, 0), time.Time{})
+		if len(got) != 1 || got[0].RunID != 35 {
+			t.Errorf("got %v want only run 35", got)
+		}
+
+		// The History survives a reload from disk
+		reloaded := reopen()
+
+		got = reloaded.GetHistory("Craque", time.Time{}, time.Time{})
+		if diff := cmp.Diff(got, want); diff != "" {
+			t.Error(diff)
+		}
+		assertIDEquals(t, reloaded.GetTriggerID("Craque"), 35)
+	})
+
+	t.Run("store the Kind and System of parts", func(t *testing.T) {
+		store, reopen := newStore(t, `[
+			{"Name": "core", "LastID": 10, "Score": 99}]`)
+
+		store.TriggerID("component:core-braze", &VerifyResult{Kind: KindComponent, System: "core", Datetime: 1000, Score: 99})
+
+		for _, s := range []suiteStore{store, reopen()} {
+			got := s.GetAlmanac().Find("component:core-braze")
+			if got == nil || got.Kind != KindComponent || got.System != "core" {
+				t.Errorf("got %+v want a component of core", got)
+			}
+			runs := s.GetHistory("component:core-braze", time.Time{}, time.Time{})
+			if len(runs) != 1 || runs[0].Kind != KindComponent || runs[0].System != "core" {
+				t.Errorf("got runs %+v want one run of a component of core", runs)
+			}
+		}
+	})
+
+	t.Run("store the Policy of each run", func(t *testing.T) {
+		store, reopen := newStore(t, `[]`)
+
+		store.TriggerID("component:ad-server-bidder", &VerifyResult{Kind: KindComponent, Policy: "experimental", Datetime: 1000, Score: 100})
+
+		for _, s := range []suiteStore{store, reopen()} {
+			runs := s.GetHistory("component:ad-server-bidder", time.Time{}, time.Time{})
+			if len(runs) != 1 || runs[0].Policy != "experimental" {
+				t.Errorf("got runs %+v want one run under the experimental Policy", runs)
+			}
+		}
 	})
 
 	t.Run("almanac sorted", func(t *testing.T) {
-		database, cleanDatabase := createTempFile(t, `[
+		store, _ := newStore(t, `[
 			{"Name": "Mattic", "LastID": 10, "Score": 5},
 			{"Name": "Craque", "LastID": 33, "Score": 4}]`)
-		defer cleanDatabase()
-
-		store, err := NewFSStore(database)
-
-		assertNoError(t, err)
 
 		got := store.GetAlmanac()
 		want := Almanac{
-			{"Craque", 33, 4},
-			{"Mattic", 10, 5},
+			{Name: "Craque", LastID: 33, Score: 4},
+			{Name: "Mattic", LastID: 10, Score: 5},
 		}
 
 		assertAlmanac(t, got, want)
@@ -176,16 +286,53 @@ func TestFileSystemStore(t *testing.T) {
 		assertAlmanac(t, got, want)
 	})
 
-	t.Run("correctly return zero when TriggerID is not found", func(t *testing.T) {
-		database, cleanDatabase := createTempFile(t, `[
+	t.Run("almanac is a copy", func(t *testing.T) {
+		store, _ := newStore(t, `[
+			{"Name": "Mattic", "LastID": 10, "Score": 99, "Principles": {"reliability": 99}}]`)
+
+		changed := store.GetAlmanac()
+		changed[0].Score = 1
+		changed[0].Principles[Reliability] = 1
+
+		got := store.GetAlmanac()
+		want := Almanac{{Name: "Mattic", LastID: 10, Score: 99, Principles: PrincipleScores{Reliability: 99}}}
+		assertAlmanac(t, got, want)
+	})
+
+	t.Run("concurrent runs are all counted", func(t *testing.T) {
+		store, reopen := newStore(t, `[
 			{"Name": "Mattic", "LastID": 10, "Score": 99},
 			{"Name": "Craque", "LastID": 33, "Score": 98}]`)
-		defer cleanDatabase()
 
-		store, err := NewFSStore(database)
-		if err != nil {
-			log.Fatalf("New services: problem creating file system service store, %v ", err)
+		// Like the README batch loop, but in parallel
+		var wg sync.WaitGroup
+		for i := 0; i < 20; i++ {
+			wg.Add(1)
+			go func(i int) {
+				defer wg.Done()
+				store.TriggerID("Craque", &VerifyResult{Score: 99})
+				store.TriggerID(fmt.Sprintf("Pepper-%d", i%4), &VerifyResult{Score: 99})
+				store.GetAlmanac()
+				store.GetTriggerID("Craque")
+				store.GetHistory("Craque", time.Time{}, time.Time{})
+			}(i)
 		}
+		wg.Wait()
+
+		assertIDEquals(t, store.GetTriggerID("Craque"), 53)
+		assertIDEquals(t, len(store.GetHistory("Craque", time.Time{}, time.Time{})), 20)
+		assertIDEquals(t, store.GetTriggerID("Pepper-0"), 5)
+
+		// What's on disk is whole and matches
+		reloaded := reopen()
+		assertIDEquals(t, reloaded.GetTriggerID("Craque"), 53)
+		assertIDEquals(t, len(reloaded.GetAlmanac()), 6)
+	})
+
+	t.Run("correctly return zero when TriggerID is not found", func(t *testing.T) {
+		store, _ := newStore(t, `[
+			{"Name": "Mattic", "LastID": 10, "Score": 99},
+			{"Name": "Craque", "LastID": 33, "Score": 98}]`)
 
 		// Trying to get a service that isn't in the database
 		// will cause the function to return 0
@@ -195,15 +342,9 @@ func TestFileSystemStore(t *testing.T) {
 	})
 
 	t.Run("correctly return zero when GetScore is not found", func(t *testing.T) {
-		database, cleanDatabase := createTempFile(t, `[
+		store, _ := newStore(t, `[
 			{"Name": "Mattic", "LastID": 10, "Score": 99},
 			{"Name": "Craque", "LastID": 33, "Score": 98}]`)
-		defer cleanDatabase()
-
-		store, err := NewFSStore(database)
-		if err != nil {
-			log.Fatalf("Store LastID: problem creating file system service store, %v ", err)
-		}
 
 		// If the service doesn't exist, getting its score will return 0
 		got := store.GetScore("Pepper")
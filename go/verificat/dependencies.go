@@ -0,0 +1,282 @@
+package main
+
+import (
+	"errors"
+	"log/slog"
+	"sort"
+	"strings"
+
+	"github.com/tdabasinskas/go-backstage/v2/backstage"
+)
+
+// Lifecycles a Component or API declares in Backstage.
+const (
+	LifecycleProduction   = "production"
+	LifecycleExperimental = "experimental"
+)
+
+// dependencyFields are where an entity lists what it relies on,
+// and the kind a reference is when it doesn't say.
+var dependencyFields = []struct {
+	Field       string
+	DefaultKind string
+}{
+	{"dependsOn", KindComponent},
+	{"consumesApis", KindAPI},
+}
+
+var errCatalogUnavailable = errors.New("catalog unavailable")
+
+// Dependency is one entity a service relies on, and where that's declared.
+type Dependency struct {
+	Name  string // As the service is stored, e.g.: resource:core-db
+	Field string // Where it's declared, e.g.: spec.dependsOn
+	Via   string `json:",omitempty"` // The part that declares it, when the service is a System
+}
+
+// DependencyGraph is who depends on whom across the catalog,
+// read from each entity's spec.dependsOn and spec.consumesApis.
+// Entities are keyed by the name they're stored under, e.g.: core or component:core-braze.
+type DependencyGraph struct {
+	entities map[string]backstage.Entity
+	edges    map[string][]Dependency
+}
+
+// BuildDependencyGraph reads every System, Component, Resource and API in the catalog.
+func BuildDependencyGraph(c Catalog) (*DependencyGraph, error) {
+	entities, err := c.ListEntities("kind=system", "kind=component", "kind=resource", "kind=api")
+	if err != nil {
+		slog.Error("Failed to read the catalog for dependencies", slog.Any("Error", err))
+		return nil, err
+	}
+
+	g := &DependencyGraph{entities: make(map[string]backstage.Entity), edges: make(map[string][]Dependency)}
+	for _, e := range entities {
+		name := EntityRef{Kind: strings.ToLower(e.Kind), Name: e.Metadata.Name}.String()
+		g.entities[name] = e
+
+		for _, f := range dependencyFields {
+			for _, raw := range specStrings(e, f.Field) {
+				ref, err := relationRef(raw, f.DefaultKind)
+				if err != nil {
+					// e.g.: a Group, which isn't verified
+					continue
+				}
+				g.edges[name] = append(g.edges[name], Dependency{Name: ref.String(), Field: "spec." + f.Field})
+			}
+		}
+	}
+	slog.Debug("Dependency Graph Built", slog.Int("Entities", len(g.entities)))
+	return g, nil
+}
+
+// relationRef reads a reference from a relation field, where a bare name is /defaultKind/.
+func relationRef(raw, defaultKind string) (EntityRef, error) {
+	if !strings.Contains(raw, ":") {
+		raw = defaultKind + ":" + raw
+	}
+	return ParseEntityRef(raw)
+}
+
+// specStrings reads a list of strings from the entity's spec.
+func specStrings(e backstage.Entity, field string) []string {
+	values, _ := e.Spec[field].([]any)
+	var out []string
+	for _, v := range values {
+		if s, ok := v.(string); ok && s != "" {
+			out = append(out, s)
+		}
+	}
+	return out
+}
+
+// specString reads a string from the entity's spec.
+func specString(e backstage.Entity, field string) string {
+	s, _ := e.Spec[field].(string)
+	return s
+}
+
+// InCatalog is true when the service is in the catalog.
+func (g *DependencyGraph) InCatalog(name string) bool {
+	_, ok := g.entities[name]
+	return ok
+}
+
+// Lifecycle is the service's spec.lifecycle.
+// A System has none of its own, it's in production when any of its parts are.
+func (g *DependencyGraph) Lifecycle(name string) string {
+	e, ok := g.entities[name]
+	if !ok {
+		return ""
+	}
+	if !strings.EqualFold(e.Kind, KindSystem) {
+		return specString(e, "lifecycle")
+	}
+
+	lifecycle := ""
+	for _, part := range g.partsOf(e.Metadata.Name) {
+		switch l := g.Lifecycle(part); {
+		case l == LifecycleProduction:
+			return l
+		case lifecycle == "":
+			lifecycle = l
+		}
+	}
+	return lifecycle
+}
+
+// partsOf lists the Components, Resources and APIs that belong to /system/, sorted by name:
+// those whose spec.system is the System, and those without a System of their own
+// that are a subcomponentOf one of its parts, or that one of its parts dependsOn.
+func (g *DependencyGraph) partsOf(system string) []string {
+	member := make(map[string]bool)
+	for name, e := range g.entities {
+		if !strings.EqualFold(e.Kind, KindSystem) && entityRefName(specString(e, "system")) == system {
+			member[name] = true
+		}
+	}
+
+	// A part joining can bring its own subcomponents and dependencies along
+	for joined := true; joined; {
+		joined = false
+		for name, e := range g.entities {
+			if member[name] || strings.EqualFold(e.Kind, KindSystem) || specString(e, "system") != "" {
+				continue
+			}
+			if g.joins(name, e, member) {
+				member[name] = true
+				joined = true
+			}
+		}
+	}
+
+	parts := make([]string, 0, len(member))
+	for name := range member {
+		parts = append(parts, name)
+	}
+	sort.Strings(parts)
+	return parts
+}
+
+// joins is true when the entity is a subcomponentOf one of the /member/ parts, or one of them dependsOn it.
+func (g *DependencyGraph) joins(name string, e backstage.Entity, member map[string]bool) bool {
+	if parent := specString(e, "subcomponentOf"); parent != "" {
+		if ref, err := relationRef(parent, KindComponent); err == nil && member[ref.String()] {
+			return true
+		}
+	}
+	for part := range member {
+		for _, dep := range g.edges[part] {
+			if dep.Name == name && dep.Field == "spec.dependsOn" {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// systems lists every System in the catalog, sorted by name.
+func (g *DependencyGraph) systems() []string {
+	var systems []string
+	for name, e := range g.entities {
+		if strings.EqualFold(e.Kind, KindSystem) {
+			systems = append(systems, name)
+		}
+	}
+	sort.Strings(systems)
+	return systems
+}
+
+// DependenciesOf lists what the service relies on, sorted by name.
+// A System relies on whatever its parts do outside of the System.
+func (g *DependencyGraph) DependenciesOf(name string) []Dependency {
+	e, ok := g.entities[name]
+	if !ok {
+		return nil
+	}
+	if !strings.EqualFold(e.Kind, KindSystem) {
+		deps := append([]Dependency(nil), g.edges[name]...)
+		sort.SliceStable(deps, func(i, j int) bool { return deps[i].Name < deps[j].Name })
+		return deps
+	}
+
+	parts := g.partsOf(e.Metadata.Name)
+	internal := map[string]bool{name: true}
+	for _, part := range parts {
+		internal[part] = true
+	}
+
+	var deps []Dependency
+	seen := make(map[string]bool)
+	for _, part := range parts {
+		for _, dep := range g.edges[part] {
+			if internal[dep.Name] || seen[dep.Name] {
+				continue
+			}
+			seen[dep.Name] = true
+			dep.Via = part
+			deps = append(deps, dep)
+		}
+	}
+	sort.SliceStable(deps, func(i, j int) bool { return deps[i].Name < deps[j].Name })
+	return deps
+}
+
+// UpstreamStatus is how ready one of a service's dependencies is.
+type UpstreamStatus struct {
+	Dependency
+	InCatalog bool      // Missing from the catalog entirely when false
+	Lifecycle string    `json:",omitempty"`
+	Verified  bool      // Whether Verificat has a result for it
+	Score     int       `json:",omitempty"` // Its latest Score
+	Status    Readiness `json:",omitempty"` // Its latest Status
+}
+
+// DependencyReport is every dependency of a service with its latest readiness.
+type DependencyReport struct {
+	Service       string
+	Dependencies  []UpstreamStatus
+	BelowBaseline []string // Dependencies that are NotReady, they fail the Required Baseline
+}
+
+// Report looks up the latest result of each of the service's dependencies in the Almanac.
+// It returns false when the service isn't in the catalog.
+func (g *DependencyGraph) Report(name string, almanac Almanac) (DependencyReport, bool) {
+	if !g.InCatalog(name) {
+		return DependencyReport{}, false
+	}
+
+	report := DependencyReport{Service: name, Dependencies: []UpstreamStatus{}, BelowBaseline: []string{}}
+	for _, dep := range g.DependenciesOf(name) {
+		upstream := UpstreamStatus{Dependency: dep, InCatalog: g.InCatalog(dep.Name), Lifecycle: g.Lifecycle(dep.Name)}
+		if service := almanac.Find(dep.Name); service != nil {
+			upstream.Verified = true
+			upstream.Score = service.Score
+			upstream.Status = service.Status
+		}
+		if upstream.Status == NotReady {
+			report.BelowBaseline = append(report.BelowBaseline, dep.Name)
+		}
+		report.Dependencies = append(report.Dependencies, upstream)
+	}
+	return report, true
+}
+
+// dependenciesRead is the catalog's dependency graph, read once per run.
+type dependenciesRead struct {
+	graph *DependencyGraph
+	err   error
+}
+
+// readDependencies builds the dependency graph the first time it's asked for.
+func (s *SvcTestDB) readDependencies() (*DependencyGraph, error) {
+	if s.dependencies == nil {
+		if s.Catalog == nil {
+			s.dependencies = &dependenciesRead{err: errCatalogUnavailable}
+		} else {
+			graph, err := BuildDependencyGraph(s.Catalog)
+			s.dependencies = &dependenciesRead{graph: graph, err: err}
+		}
+	}
+	return s.dependencies.graph, s.dependencies.err
+}
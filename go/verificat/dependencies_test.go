@@ -0,0 +1,174 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+// The dependency graph follows spec.dependsOn and spec.consumesApis through the catalog
+func TestDependencyGraph(t *testing.T) {
+	c, err := NewBackstageCatalog(newFakeBackstage(t, fakeBackstageFixtures))
+	assertError(t, err, nil)
+	g, err := BuildDependencyGraph(c)
+	assertError(t, err, nil)
+
+	t.Run("bare references default to their field's kind", func(t *testing.T) {
+		want := []Dependency{
+			{Name: "api:ad-server-api", Field: "spec.consumesApis"},
+			{Name: "resource:core-db", Field: "spec.dependsOn"},
+		}
+		if diff := cmp.Diff(g.DependenciesOf("component:core-braze"), want); diff != "" {
+			t.Error(diff)
+		}
+	})
+
+	t.Run("a System depends on what its parts do outside of it", func(t *testing.T) {
+		want := []Dependency{{Name: "api:ad-server-api", Field: "spec.consumesApis", Via: "component:core-braze"}}
+		if diff := cmp.Diff(g.DependenciesOf("core"), want); diff != "" {
+			t.Error(diff)
+		}
+
+		// The bidder is part of ad-server too, only the Redis is outside
+		want = []Dependency{{Name: "resource:ad-server-redis", Field: "spec.dependsOn", Via: "component:ad-server-cache-builder"}}
+		if diff := cmp.Diff(g.DependenciesOf("ad-server"), want); diff != "" {
+			t.Error(diff)
+		}
+	})
+
+	t.Run("a System is in production when any of its parts are", func(t *testing.T) {
+		assertString(t, g.Lifecycle("ad-server"), LifecycleProduction)
+		assertString(t, g.Lifecycle("component:ad-server-bidder"), LifecycleExperimental)
+		assertString(t, g.Lifecycle("weedmaps-api"), "")
+	})
+
+	t.Run("the report marks dependencies below the Required Baseline", func(t *testing.T) {
+		almanac := Almanac{
+			{Name: "api:ad-server-api", Kind: KindAPI, System: "ad-server", LastID: 1, Score: 97, Status: NotReady},
+			{Name: "resource:core-db", Kind: KindResource, System: "core", LastID: 1, Score: 100, Status: Ready},
+		}
+		report, ok := g.Report("component:core-braze", almanac)
+		if !ok {
+			t.Fatal("got no report for component:core-braze")
+		}
+		want := DependencyReport{
+			Service: "component:core-braze",
+			Dependencies: []UpstreamStatus{
+				{Dependency: Dependency{Name: "api:ad-server-api", Field: "spec.consumesApis"}, InCatalog: true, Lifecycle: LifecycleProduction, Verified: true, Score: 97, Status: NotReady},
+				{Dependency: Dependency{Name: "resource:core-db", Field: "spec.dependsOn"}, InCatalog: true, Verified: true, Score: 100, Status: Ready},
+			},
+			BelowBaseline: []string{"api:ad-server-api"},
+		}
+		if diff := cmp.Diff(report, want); diff != "" {
+			t.Error(diff)
+		}
+
+		if _, ok := g.Report("component:nobody", almanac); ok {
+			t.Error("got a report for a service outside the catalog")
+		}
+	})
+}
+
+// A System's parts are found through spec.system, then subcomponentOf and dependsOn
+func TestDependencyGraphParts(t *testing.T) {
+	dir := t.TempDir()
+	catalog := `apiVersion: backstage.io/v1alpha1
+kind: System
+metadata:
+  name: shop
+---
+apiVersion: backstage.io/v1alpha1
+kind: Component
+metadata:
+  name: shop-web
+spec:
+  system: shop
+  dependsOn: [resource:shop-db, component:payments]
+---
+apiVersion: backstage.io/v1alpha1
+kind: Component
+metadata:
+  name: shop-worker
+spec:
+  subcomponentOf: shop-web
+  dependsOn: [resource:shop-cache]
+---
+apiVersion: backstage.io/v1alpha1
+kind: Resource
+metadata:
+  name: shop-db
+---
+apiVersion: backstage.io/v1alpha1
+kind: Resource
+metadata:
+  name: shop-cache
+---
+# Its own System's, whoever depends on it
+apiVersion: backstage.io/v1alpha1
+kind: Component
+metadata:
+  name: payments
+spec:
+  system: billing
+`
+	assertNoError(t, os.WriteFile(filepath.Join(dir, "entities.yaml"), []byte(catalog), 0o644))
+
+	c, err := NewBackstageCatalog(newFakeBackstage(t, dir))
+	assertError(t, err, nil)
+	g, err := BuildDependencyGraph(c)
+	assertError(t, err, nil)
+
+	want := []string{"component:shop-web", "component:shop-worker", "resource:shop-cache", "resource:shop-db"}
+	if diff := cmp.Diff(g.partsOf("shop"), want); diff != "" {
+		t.Error(diff)
+	}
+	if diff := cmp.Diff(g.systems(), []string{"shop"}); diff != "" {
+		t.Error(diff)
+	}
+}
+
+// Production services are penalised for experimental or missing dependencies
+func TestDependencyCheck(t *testing.T) {
+	c, err := NewBackstageCatalog(newFakeBackstage(t, fakeBackstageFixtures))
+	assertError(t, err, nil)
+
+	checkTests := []struct {
+		Service  string
+		Score    int
+		Works    bool
+		Findings []Finding
+	}{
+		{"component:core-braze", 100, true, nil},
+		{"component:ad-server-bidder", 100, true, nil}, // Experimental itself
+		{"component:ad-server-cache-builder", 99, false, []Finding{
+			{Path: "spec.dependsOn", Message: "component:ad-server-cache-builder depends on component:ad-server-bidder, which is experimental"},
+			{Path: "spec.dependsOn", Message: "component:ad-server-cache-builder depends on resource:ad-server-redis, which is not in the catalog"},
+		}},
+		{"ad-server", 99, false, []Finding{
+			{Path: "spec.dependsOn", Message: "component:ad-server-cache-builder depends on resource:ad-server-redis, which is not in the catalog"},
+		}},
+	}
+
+	for _, tt := range checkTests {
+		t.Run(tt.Service, func(t *testing.T) {
+			s := &SvcTestDB{Service: tt.Service, Catalog: c, Score: 100}
+			tr := dependencyCheck{}.TestItem(s)
+			if tr.Works != tt.Works || s.Score != tt.Score {
+				t.Errorf("got works %t, score %d want works %t, score %d", tr.Works, s.Score, tt.Works, tt.Score)
+			}
+			if diff := cmp.Diff(tr.Findings, tt.Findings); diff != "" {
+				t.Error(diff)
+			}
+		})
+	}
+
+	t.Run("without a catalog it isn't checked", func(t *testing.T) {
+		s := &SvcTestDB{Service: "ad-server", Score: 100}
+		tr := dependencyCheck{}.TestItem(s)
+		if !tr.Works || s.Score != 100 {
+			t.Errorf("got works %t, score %d want a pass without penalty", tr.Works, s.Score)
+		}
+	})
+}
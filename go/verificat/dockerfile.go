@@ -0,0 +1,169 @@
+package main
+
+import (
+	"bufio"
+	"io"
+	"strings"
+	"unicode"
+)
+
+// Instruction is one instruction of a Dockerfile, with its continuation lines joined.
+type Instruction struct {
+	Cmd  string // Upper case, e.g.: FROM
+	Args string // Everything after the command, e.g.: golang:1.22 AS build
+	Line int    // Where the instruction starts
+}
+
+// Stage is a FROM and the instructions building on it.
+type Stage struct {
+	From         Instruction
+	Image        string // e.g.: golang:1.22@sha256:…
+	Name         string // The AS name, if it has one
+	Instructions []Instruction
+}
+
+// Dockerfile is a parsed Dockerfile, split into its build stages.
+type Dockerfile struct {
+	Stages []Stage
+	Global []Instruction // ARGs before the first FROM
+}
+
+// ParseDockerfile reads a Dockerfile the way `docker build` does, as far as hygiene goes:
+// comments and blank lines are skipped, a trailing backslash continues an instruction,
+// and instructions are case-insensitive.
+// Heredocs and escape directives other than the backslash aren't supported.
+func ParseDockerfile(r io.Reader) (*Dockerfile, error) {
+	d := new(Dockerfile)
+
+	var pending *Instruction
+	add := func(in Instruction) {
+		if in.Cmd == "FROM" {
+			d.Stages = append(d.Stages, newStage(in))
+			return
+		}
+		if len(d.Stages) == 0 {
+			d.Global = append(d.Global, in)
+			return
+		}
+		last := &d.Stages[len(d.Stages)-1]
+		last.Instructions = append(last.Instructions, in)
+	}
+
+	scanner := bufio.NewScanner(r)
+	for n := 1; scanner.Scan(); n++ {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			// Comments may sit between continuation lines without ending the instruction
+			continue
+		}
+
+		continued := strings.HasSuffix(line, `\`)
+		line = strings.TrimSpace(strings.TrimSuffix(line, `\`))
+
+		if pending == nil {
+			cmd, args := line, ""
+			if i := strings.IndexFunc(line, unicode.IsSpace); i > 0 {
+				cmd, args = line[:i], line[i:]
+			}
+			pending = &Instruction{Cmd: strings.ToUpper(cmd), Args: strings.TrimSpace(args), Line: n}
+		} else {
+			pending.Args = strings.TrimSpace(pending.Args + " " + line)
+		}
+
+		if !continued {
+			add(*pending)
+			pending = nil
+		}
+	}
+	if pending != nil {
+		add(*pending)
+	}
+	return d, scanner.Err()
+}
+
+// newStage reads a FROM, e.g.: FROM --platform=$BUILDPLATFORM golang:1.22 AS build
+func newStage(from Instruction) Stage {
+	s := Stage{From: from}
+	fields := strings.Fields(from.Args)
+	for len(fields) > 0 && strings.HasPrefix(fields[0], "--") {
+		fields = fields[1:]
+	}
+	if len(fields) > 0 {
+		s.Image = fields[0]
+	}
+	if len(fields) == 3 && strings.EqualFold(fields[1], "AS") {
+		s.Name = fields[2]
+	}
+	return s
+}
+
+// Final is the stage that becomes the image.
+func (d *Dockerfile) Final() *Stage {
+	if len(d.Stages) == 0 {
+		return nil
+	}
+	return &d.Stages[len(d.Stages)-1]
+}
+
+// Last is the stage's last instruction of /cmd/, nil if it has none.
+func (s *Stage) Last(cmd string) *Instruction {
+	for i := len(s.Instructions) - 1; i >= 0; i-- {
+		if s.Instructions[i].Cmd == cmd {
+			return &s.Instructions[i]
+		}
+	}
+	return nil
+}
+
+// keyValue is one pair of a LABEL or ENV.
+type keyValue struct {
+	Key, Value string
+}
+
+// keyValues reads the arguments of LABEL or ENV, either key=value pairs or the legacy single `key value`.
+// Quotes are removed from keys and values.
+func keyValues(args string) []keyValue {
+	words := splitWords(args)
+	if len(words) > 0 && !strings.Contains(words[0], "=") {
+		return []keyValue{{words[0], strings.Join(words[1:], " ")}}
+	}
+	var pairs []keyValue
+	for _, w := range words {
+		k, v, _ := strings.Cut(w, "=")
+		pairs = append(pairs, keyValue{k, v})
+	}
+	return pairs
+}
+
+// splitWords splits on whitespace outside of quotes, dropping the quotes,
+// e.g.: a="b c" d is a=b c and d.
+func splitWords(s string) []string {
+	var words []string
+	var word strings.Builder
+	var quote rune
+	inWord := false
+	for _, r := range s {
+		switch {
+		case quote != 0 && r == quote:
+			quote = 0
+		case quote != 0:
+			word.WriteRune(r)
+		case r == '"' || r == '\'':
+			quote = r
+			inWord = true
+		case unicode.IsSpace(r):
+			if inWord {
+				words = append(words, word.String())
+				word.Reset()
+				inWord = false
+			}
+		default:
+			word.WriteRune(r)
+			inWord = true
+		}
+	}
+	if inWord {
+		words = append(words, word.String())
+	}
+	return words
+}
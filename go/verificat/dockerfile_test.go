@@ -0,0 +1,71 @@
+package main
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+// Instructions are split into stages, with continuations joined and comments skipped
+func TestParseDockerfile(t *testing.T) {
+	dockerfile := `# syntax=docker/dockerfile:1
+ARG GO_VERSION=1.22
+
+FROM --platform=$BUILDPLATFORM golang:${GO_VERSION} AS build
+run go build \
+    # a comment inside the instruction
+    -o /bin/app
+from	gcr.io/distroless/static AS final
+COPY --from=build /bin/app /bin/app
+USER nonroot`
+
+	d, err := ParseDockerfile(strings.NewReader(dockerfile))
+	assertNoError(t, err)
+
+	want := &Dockerfile{
+		Global: []Instruction{{Cmd: "ARG", Args: "GO_VERSION=1.22", Line: 2}},
+		Stages: []Stage{
+			{
+				From:         Instruction{Cmd: "FROM", Args: "--platform=$BUILDPLATFORM golang:${GO_VERSION} AS build", Line: 4},
+				Image:        "golang:${GO_VERSION}",
+				Name:         "build",
+				Instructions: []Instruction{{Cmd: "RUN", Args: "go build -o /bin/app", Line: 5}},
+			},
+			{
+				From:  Instruction{Cmd: "FROM", Args: "gcr.io/distroless/static AS final", Line: 8},
+				Image: "gcr.io/distroless/static",
+				Name:  "final",
+				Instructions: []Instruction{
+					{Cmd: "COPY", Args: "--from=build /bin/app /bin/app", Line: 9},
+					{Cmd: "USER", Args: "nonroot", Line: 10},
+				},
+			},
+		},
+	}
+	if diff := cmp.Diff(d, want); diff != "" {
+		t.Error(diff)
+	}
+	assertString(t, d.Final().Last("USER").Args, "nonroot")
+	if d.Final().Last("HEALTHCHECK") != nil {
+		t.Error("found a HEALTHCHECK that isn't there")
+	}
+}
+
+// LABEL and ENV take key=value pairs, or a single key and value
+func TestKeyValues(t *testing.T) {
+	kvTests := []struct {
+		Args string
+		Want []keyValue
+	}{
+		{`a=1 b="two words" c=`, []keyValue{{"a", "1"}, {"b", "two words"}, {"c", ""}}},
+		{`org.opencontainers.image.source https://github.com/GhostGroup/verificat`, []keyValue{{"org.opencontainers.image.source", "https://github.com/GhostGroup/verificat"}}},
+		{`GREETING 'hello there'`, []keyValue{{"GREETING", "hello there"}}},
+		{``, nil},
+	}
+	for _, tt := range kvTests {
+		if diff := cmp.Diff(keyValues(tt.Args), tt.Want); diff != "" {
+			t.Errorf("%q %s", tt.Args, diff)
+		}
+	}
+}
@@ -0,0 +1,72 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// Kinds of catalog entity Verificat can verify.
+// A System is a whole Weedmaps Service, the others are its parts.
+const (
+	KindSystem    = "system"
+	KindComponent = "component"
+	KindResource  = "resource"
+	KindAPI       = "api"
+)
+
+var KindNotVerified = errors.New("only Systems, Components, Resources and APIs can be verified")
+
+// EntityRef names a catalog entity to verify, written kind:name, e.g.: component:core-braze.
+// A bare name is a System, which is how every service was named before the other kinds were verified.
+type EntityRef struct {
+	Kind string
+	Name string
+}
+
+// ParseEntityRef reads a service name as an EntityRef.
+// The kind is case-insensitive, and the default namespace can be given (component:default/core-braze)
+// since Verificat only reads that one.
+func ParseEntityRef(service string) (EntityRef, error) {
+	kind, name, found := strings.Cut(service, ":")
+	if !found {
+		kind, name = KindSystem, service
+	}
+	kind = strings.ToLower(kind)
+
+	if ns, n, ok := strings.Cut(name, "/"); ok {
+		if ns != "default" {
+			return EntityRef{}, fmt.Errorf("%q is not in the default namespace", service)
+		}
+		name = n
+	}
+	if name == "" {
+		return EntityRef{}, fmt.Errorf("%q has no name", service)
+	}
+
+	switch kind {
+	case KindSystem, KindComponent, KindResource, KindAPI:
+		return EntityRef{Kind: kind, Name: name}, nil
+	}
+	return EntityRef{}, fmt.Errorf("%w, not %q", KindNotVerified, kind)
+}
+
+// String is the name a service is stored under, bare for a System.
+func (r EntityRef) String() string {
+	if r.Kind == KindSystem {
+		return r.Name
+	}
+	return r.Kind + ":" + r.Name
+}
+
+// entityRefName reduces a Backstage entity reference,
+// e.g.: group:default/code-owners-admin, to its name.
+func entityRefName(ref string) string {
+	if i := strings.Index(ref, ":"); i >= 0 {
+		ref = ref[i+1:]
+	}
+	if i := strings.LastIndex(ref, "/"); i >= 0 {
+		ref = ref[i+1:]
+	}
+	return ref
+}
@@ -0,0 +1,57 @@
+package main
+
+import "testing"
+
+func TestParseEntityRef(t *testing.T) {
+	refTests := []struct {
+		Service string
+		Want    EntityRef
+		Stored  string
+	}{
+		{"admin", EntityRef{KindSystem, "admin"}, "admin"},
+		{"system:admin", EntityRef{KindSystem, "admin"}, "admin"},
+		{"component:core-braze", EntityRef{KindComponent, "core-braze"}, "component:core-braze"},
+		{"Component:default/core-braze", EntityRef{KindComponent, "core-braze"}, "component:core-braze"},
+		{"resource:core-db", EntityRef{KindResource, "core-db"}, "resource:core-db"},
+		{"API:ad-server-api", EntityRef{KindAPI, "ad-server-api"}, "api:ad-server-api"},
+	}
+
+	for _, tt := range refTests {
+		t.Run(tt.Service, func(t *testing.T) {
+			got, err := ParseEntityRef(tt.Service)
+			assertNoError(t, err)
+			if got != tt.Want {
+				t.Errorf("got %+v want %+v", got, tt.Want)
+			}
+			assertString(t, got.String(), tt.Stored)
+		})
+	}
+
+	t.Run("only verifiable kinds", func(t *testing.T) {
+		_, err := ParseEntityRef("group:code-owners-core")
+		assertError(t, err, KindNotVerified)
+	})
+
+	t.Run("only the default namespace", func(t *testing.T) {
+		if _, err := ParseEntityRef("component:sandbox/core-braze"); err == nil {
+			t.Error("expected an error for another namespace")
+		}
+	})
+
+	t.Run("a name is needed", func(t *testing.T) {
+		if _, err := ParseEntityRef("component:"); err == nil {
+			t.Error("expected an error for no name")
+		}
+	})
+}
+
+func TestEntityRefName(t *testing.T) {
+	for ref, want := range map[string]string{
+		"code-owners-core":               "code-owners-core",
+		"group:code-owners-core":         "code-owners-core",
+		"group:default/code-owners-core": "code-owners-core",
+		"system:default/ad-server":       "ad-server",
+	} {
+		assertString(t, entityRefName(ref), want)
+	}
+}
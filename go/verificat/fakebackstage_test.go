@@ -0,0 +1,158 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+
+	"gopkg.in/yaml.v3"
+)
+
+// fakeBackstageFixtures are the entities the fake Backstage serves by default.
+const fakeBackstageFixtures = "testdata/backstage"
+
+// fakeBackstage is an in-process Backstage catalog API,
+// serving the entity YAML (catalog-info.yaml format) found in a fixture directory.
+// Only the endpoints Verificat reads are served.
+type fakeBackstage struct {
+	entities []map[string]any
+}
+
+// newFakeBackstage starts a fake Backstage for the test, returning its URL.
+func newFakeBackstage(t testing.TB, dir string) string {
+	t.Helper()
+
+	fake := new(fakeBackstage)
+	files, err := filepath.Glob(filepath.Join(dir, "*.yaml"))
+	if err != nil || len(files) == 0 {
+		t.Fatalf("no Backstage fixtures in %s, %v", dir, err)
+	}
+	for _, file := range files {
+		if err := fake.load(file); err != nil {
+			t.Fatalf("problem loading Backstage fixture %s, %v", file, err)
+		}
+	}
+
+	server := httptest.NewServer(fake)
+	t.Cleanup(server.Close)
+	return server.URL
+}
+
+// load reads every entity in a multi-document YAML file.
+func (f *fakeBackstage) load(file string) error {
+	r, err := os.Open(file)
+	if err != nil {
+		return err
+	}
+	defer r.Close()
+
+	dec := yaml.NewDecoder(r)
+	for {
+		var entity map[string]any
+		err := dec.Decode(&entity)
+		if errors.Is(err, io.EOF) {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		if entity == nil {
+			continue
+		}
+
+		// Backstage fills in the namespace
+		metadata, _ := entity["metadata"].(map[string]any)
+		if metadata == nil {
+			return errors.New("entity has no metadata")
+		}
+		if _, ok := metadata["namespace"]; !ok {
+			metadata["namespace"] = "default"
+		}
+		f.entities = append(f.entities, entity)
+	}
+}
+
+func (f *fakeBackstage) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	const api = "/api/catalog/entities"
+
+	switch {
+	case r.URL.Path == api:
+		f.list(w, r.URL.Query()["filter"])
+	case strings.HasPrefix(r.URL.Path, api+"/by-name/"):
+		ref := strings.Split(strings.TrimPrefix(r.URL.Path, api+"/by-name/"), "/")
+		if len(ref) != 3 {
+			f.notFound(w)
+			return
+		}
+		f.get(w, ref[0], ref[1], ref[2])
+	default:
+		f.notFound(w)
+	}
+}
+
+// list serves every entity matching any of the filters,
+// each a comma-separated list of key=value conditions that must all hold, e.g.: kind=component,spec.system=core.
+func (f *fakeBackstage) list(w http.ResponseWriter, filters []string) {
+	matched := []map[string]any{}
+	for _, entity := range f.entities {
+		if len(filters) == 0 || slices.ContainsFunc(filters, func(filter string) bool { return entityMatches(entity, filter) }) {
+			matched = append(matched, entity)
+		}
+	}
+
+	w.Header().Set("content-type", jsonContentType)
+	json.NewEncoder(w).Encode(matched)
+}
+
+// entityMatches is true when every condition of the filter holds.
+func entityMatches(entity map[string]any, filter string) bool {
+	for _, condition := range strings.Split(filter, ",") {
+		key, value, _ := strings.Cut(condition, "=")
+		if !strings.EqualFold(entityField(entity, key), value) {
+			return false
+		}
+	}
+	return true
+}
+
+// get serves a single entity by its reference.
+func (f *fakeBackstage) get(w http.ResponseWriter, kind, namespace, name string) {
+	for _, entity := range f.entities {
+		if strings.EqualFold(entityField(entity, "kind"), kind) &&
+			entityField(entity, "metadata.namespace") == namespace &&
+			entityField(entity, "metadata.name") == name {
+			w.Header().Set("content-type", jsonContentType)
+			json.NewEncoder(w).Encode(entity)
+			return
+		}
+	}
+	f.notFound(w)
+}
+
+// notFound answers the way Backstage does.
+func (f *fakeBackstage) notFound(w http.ResponseWriter) {
+	w.Header().Set("content-type", jsonContentType)
+	w.WriteHeader(http.StatusNotFound)
+	io.WriteString(w, `{"error": {"name": "NotFoundError", "message": "entity not found"}}`)
+}
+
+// entityField reads a dotted field path, e.g.: metadata.name, as a string.
+func entityField(entity map[string]any, path string) string {
+	var value any = entity
+	for _, key := range strings.Split(path, ".") {
+		m, ok := value.(map[string]any)
+		if !ok {
+			return ""
+		}
+		value = m[key]
+	}
+	s, _ := value.(string)
+	return s
+}
@@ -0,0 +1,264 @@
+package main
+
+import (
+	"crypto/sha1"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+// fakeGitHubFixtures are the repositories the fake GitHub serves by default,
+// laid out as testdata/github/<owner>/<repo>/<files>.
+// Branch protection is kept apart from the files, as testdata/github/.protection/<owner>/<repo>/<branch>.yaml.
+const fakeGitHubFixtures = "testdata/github"
+
+const fakeGitHubRateLimit = 5000
+
+// fakeGitHubRepos is an in-process GitHub API serving repositories from a fixture directory.
+// Only the endpoints Verificat reads are served:
+// repositories, their contents, branches and their protection, and the rate limit.
+type fakeGitHubRepos struct {
+	dir   string
+	reset time.Time
+
+	mu               sync.Mutex
+	remaining        int
+	protectionStatus int      // Answer protection with this status instead, e.g.: 404 like GitHub does a token without administration access
+	requests         []string // Paths requested, in order
+}
+
+// newFakeGitHub starts a fake GitHub for the test,
+// returning a client for it that never sleeps.
+func newFakeGitHub(t testing.TB, dir string) (*GitHubClient, *fakeGitHubRepos) {
+	t.Helper()
+
+	if _, err := os.Stat(dir); err != nil {
+		t.Fatalf("no GitHub fixtures in %s, %v", dir, err)
+	}
+	fake := &fakeGitHubRepos{dir: dir, reset: time.Now().Add(time.Hour), remaining: fakeGitHubRateLimit}
+
+	server := httptest.NewServer(fake)
+	t.Cleanup(server.Close)
+
+	gh := NewGitHubClient(server.URL)
+	gh.Token = func() (string, error) { return "test-token", nil }
+	gh.sleep = func(time.Duration) {}
+	return gh, fake
+}
+
+// withDefaultGitHub swaps in /gh/ as the app's shared client for the rest of the test.
+func withDefaultGitHub(t testing.TB, gh *GitHubClient) {
+	original := defaultGitHub
+	defaultGitHub = gh
+	t.Cleanup(func() { defaultGitHub = original })
+}
+
+// Requests returns the paths requested so far.
+func (f *fakeGitHubRepos) Requests() []string {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return append([]string(nil), f.requests...)
+}
+
+func (f *fakeGitHubRepos) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	f.mu.Lock()
+	f.requests = append(f.requests, r.URL.Path)
+	f.mu.Unlock()
+
+	// The fixtures are private repositories, which GitHub hides from anonymous requests
+	if r.Header.Get("Authorization") == "" {
+		f.answer(w, r, http.StatusNotFound, `{"message": "Not Found"}`)
+		return
+	}
+
+	if r.URL.Path == "/rate_limit" {
+		f.rateLimit(w)
+		return
+	}
+
+	// /repos/<owner>/<repo>[/contents/<path>]
+	parts := strings.SplitN(strings.TrimPrefix(r.URL.Path, ghPreURI), "/", 3)
+	if !strings.HasPrefix(r.URL.Path, ghPreURI) || len(parts) < 2 || !f.isRepo(parts[0], parts[1]) {
+		f.answer(w, r, http.StatusNotFound, `{"message": "Not Found"}`)
+		return
+	}
+	owner, repo := parts[0], parts[1]
+
+	switch {
+	case len(parts) == 2:
+		f.repo(w, r, owner, repo)
+	case parts[2] == "contents" || strings.HasPrefix(parts[2], "contents/"):
+		f.contents(w, r, owner, repo, strings.TrimPrefix(parts[2], "contents"))
+	case strings.HasPrefix(parts[2], "branches/") && strings.HasSuffix(parts[2], "/protection"):
+		f.protection(w, r, owner, repo, strings.TrimSuffix(strings.TrimPrefix(parts[2], "branches/"), "/protection"))
+	case strings.HasPrefix(parts[2], "branches/"):
+		f.branch(w, r, owner, repo, strings.TrimPrefix(parts[2], "branches/"))
+	default:
+		f.answer(w, r, http.StatusNotFound, `{"message": "Not Found"}`)
+	}
+}
+
+// isRepo is true when the fixtures hold /owner/repo/.
+func (f *fakeGitHubRepos) isRepo(owner, repo string) bool {
+	if owner == "" || repo == "" || strings.HasPrefix(owner, ".") || strings.HasPrefix(repo, ".") {
+		return false
+	}
+	info, err := os.Stat(filepath.Join(f.dir, owner, repo))
+	return err == nil && info.IsDir()
+}
+
+// repo serves the repository's metadata.
+func (f *fakeGitHubRepos) repo(w http.ResponseWriter, r *http.Request, owner, repo string) {
+	body, _ := json.Marshal(map[string]any{
+		"name":           repo,
+		"full_name":      owner + "/" + repo,
+		"owner":          map[string]string{"login": owner},
+		"private":        true,
+		"default_branch": "main",
+	})
+	f.answer(w, r, http.StatusOK, string(body))
+}
+
+// contents serves a file raw, or a directory as a listing of its entries.
+func (f *fakeGitHubRepos) contents(w http.ResponseWriter, r *http.Request, owner, repo, file string) {
+	file = strings.Trim(path.Clean("/"+file), "/")
+	full := filepath.Join(f.dir, owner, repo, filepath.FromSlash(file))
+
+	info, err := os.Stat(full)
+	if err != nil {
+		f.answer(w, r, http.StatusNotFound, `{"message": "Not Found"}`)
+		return
+	}
+
+	if !info.IsDir() {
+		body, err := os.ReadFile(full)
+		if err != nil {
+			f.answer(w, r, http.StatusInternalServerError, `{"message": "Server Error"}`)
+			return
+		}
+		f.answer(w, r, http.StatusOK, string(body))
+		return
+	}
+
+	entries, _ := os.ReadDir(full)
+	listing := make([]map[string]string, 0, len(entries))
+	for _, e := range entries {
+		kind := "file"
+		if e.IsDir() {
+			kind = "dir"
+		}
+		listing = append(listing, map[string]string{"name": e.Name(), "path": path.Join(file, e.Name()), "type": kind})
+	}
+	body, _ := json.Marshal(listing)
+	f.answer(w, r, http.StatusOK, string(body))
+}
+
+// HideProtection makes branch protection unreadable, the way GitHub hides it from a token without administration access.
+func (f *fakeGitHubRepos) HideProtection() {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.protectionStatus = http.StatusNotFound
+}
+
+// FailProtection makes GitHub fail to answer for branch protection, after its retries too.
+func (f *fakeGitHubRepos) FailProtection() {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.protectionStatus = http.StatusInternalServerError
+}
+
+// protectionFixture is where a branch's protection is kept.
+func (f *fakeGitHubRepos) protectionFixture(owner, repo, branch string) string {
+	return filepath.Join(f.dir, ".protection", owner, repo, filepath.Base(branch)+".yaml")
+}
+
+// branch serves a branch, protected when it has a protection fixture.
+func (f *fakeGitHubRepos) branch(w http.ResponseWriter, r *http.Request, owner, repo, branch string) {
+	_, err := os.Stat(f.protectionFixture(owner, repo, branch))
+	body, _ := json.Marshal(map[string]any{"name": branch, "protected": err == nil})
+	f.answer(w, r, http.StatusOK, string(body))
+}
+
+// protection serves a branch's protection as JSON, from its YAML fixture.
+// Like GitHub, an unprotected branch is 404, and so is any branch when the protection is hidden.
+// When it's failing, every branch is a 500.
+func (f *fakeGitHubRepos) protection(w http.ResponseWriter, r *http.Request, owner, repo, branch string) {
+	f.mu.Lock()
+	status := f.protectionStatus
+	f.mu.Unlock()
+	if status != 0 {
+		f.answer(w, r, status, fmt.Sprintf(`{"message": %q}`, http.StatusText(status)))
+		return
+	}
+
+	raw, err := os.ReadFile(f.protectionFixture(owner, repo, branch))
+	if err != nil {
+		f.answer(w, r, http.StatusNotFound, `{"message": "Branch not protected"}`)
+		return
+	}
+
+	var settings map[string]any
+	if err := yaml.Unmarshal(raw, &settings); err != nil {
+		f.answer(w, r, http.StatusInternalServerError, `{"message": "Server Error"}`)
+		return
+	}
+	body, _ := json.Marshal(settings)
+	f.answer(w, r, http.StatusOK, string(body))
+}
+
+// rateLimit serves /rate_limit, which like GitHub's doesn't count against the limit.
+func (f *fakeGitHubRepos) rateLimit(w http.ResponseWriter) {
+	f.mu.Lock()
+	core := map[string]int64{
+		"limit":     fakeGitHubRateLimit,
+		"remaining": int64(f.remaining),
+		"used":      int64(fakeGitHubRateLimit - f.remaining),
+		"reset":     f.reset.Unix(),
+	}
+	f.mu.Unlock()
+
+	w.Header().Set("content-type", jsonContentType)
+	json.NewEncoder(w).Encode(map[string]any{"resources": map[string]any{"core": core}, "rate": core})
+}
+
+// answer writes a response the way GitHub does:
+// with the rate limit headers, and an ETag so an unchanged body can be answered with 304 Not Modified.
+// Like GitHub, a 304 doesn't count against the rate limit.
+func (f *fakeGitHubRepos) answer(w http.ResponseWriter, r *http.Request, status int, body string) {
+	etag := fmt.Sprintf(`"%x"`, sha1.Sum([]byte(body)))
+	notModified := status == http.StatusOK && r.Header.Get("If-None-Match") == etag
+
+	f.mu.Lock()
+	if !notModified && f.remaining > 0 {
+		f.remaining--
+	}
+	remaining := f.remaining
+	f.mu.Unlock()
+
+	w.Header().Set("X-RateLimit-Limit", strconv.Itoa(fakeGitHubRateLimit))
+	w.Header().Set("X-RateLimit-Remaining", strconv.Itoa(remaining))
+	w.Header().Set("X-RateLimit-Reset", strconv.FormatInt(f.reset.Unix(), 10))
+	w.Header().Set("X-RateLimit-Used", strconv.Itoa(fakeGitHubRateLimit-remaining))
+
+	if notModified {
+		w.WriteHeader(http.StatusNotModified)
+		return
+	}
+	if status == http.StatusOK {
+		w.Header().Set("ETag", etag)
+	}
+	w.WriteHeader(status)
+	io.WriteString(w, body)
+}
@@ -6,21 +6,21 @@ import (
 	"fmt"
 	"io"
 	"log/slog"
-	"net/http"
 	"strings"
 	"time"
 
+	"github.com/tdabasinskas/go-backstage/v2/backstage"
 	"golang.org/x/sync/errgroup"
 )
 
 const (
 	webTimeout = 10 * time.Second
-	prefixGH   = "* @GhostGroup/"
-	suffixGH   = "\n"
 )
 
+// SvcTest runs every Production Readiness check for a service
+// and hands back the combined result.
 type SvcTest interface {
-	TestItem(svc string) *TestReturn
+	TestItems(svc string) *VerifyResult
 }
 
 // SvcTestDB is the results database.
@@ -29,29 +29,99 @@ type SvcTest interface {
 // Its values are then available in runVerification,
 // which has access to this struct for adding scoring.
 type SvcTestDB struct {
-	Service  string // The service to test, e.g.: admin
-	Datetime int64  // A start timestamp
-	Owner    string // The retrieved Owner from Backstage
-	Score    int    // Score out of 100 available test points
+	Service  string                // The service to test, e.g.: admin
+	Kind     string                // The entity kind, e.g.: system or component
+	System   string                // The System a Component, Resource or API belongs to
+	Datetime int64                 // A start timestamp
+	Owner    string                // The retrieved Owner from Backstage
+	Repo     string                // The GitHub repository ("owner/name"), empty if unresolved
+	GitHub   *GitHubClient         // Where GitHub-backed Checks read from, nil uses the shared client
+	Score    int                   // Score out of 100 available test points
+	Checks   []Check               // Checks to run, nil runs everything in the registry
+	Catalog  Catalog               // Where catalog-backed Checks read from, nil skips them
+	Policy   *Policy               // Which Checks run and which are Required, nil runs them all as registered
+	Metadata *backstage.EntityMeta // The entity's metadata from Backstage, nil if it wasn't read
+
+	codeowners   *codeownersRead   // CODEOWNERS, once a Check has read it
+	dependencies *dependenciesRead // The dependency graph, once a Check has built it
+	manifests    *manifestsRead    // The Kubernetes manifests, once a Check has read them
+	outage       error             // Why GitHub couldn't answer, once a Check has run into it
 }
 
 // TestReturn holds the answers for this test
 type TestReturn struct {
-	Present bool
-	Owner   string
-	Reality string
-	Works   bool
-	Score   int
+	ID         string `json:",omitempty"` // The Check that produced this answer
+	Present    bool
+	Owner      string
+	Reality    string
+	Source     string `json:",omitempty"` // Where Reality was read from, e.g.: the CODEOWNERS path
+	Works      bool
+	Score      int
+	Principles []Principle `json:",omitempty"` // Which of the Eight Principles were tested
+	Penalty    int         `json:",omitempty"` // Points this Check took from the Score
+	Required   bool        `json:",omitempty"` // Whether this Check is part of the Required Baseline
+	Findings   []Finding   `json:",omitempty"` // The specific problems behind a failure
+}
+
+// VerifyResult is the full answer for one verification run of a service.
+type VerifyResult struct {
+	Service    string          // The service tested
+	Kind       string          `json:",omitempty"` // The entity kind, e.g.: system or component
+	System     string          `json:",omitempty"` // The System a Component, Resource or API belongs to
+	RunID      int             `json:",omitempty"` // The run ID, assigned when the result is stored
+	Datetime   int64           // When the run started
+	Score      int             // The final score after every check has run
+	Status     Readiness       // Ready, Degraded or NotReady
+	Policy     string          `json:",omitempty"` // The Policy the service was verified under
+	Principles PrincipleScores // The Score broken down by Principle
+	Results    []*TestReturn   // One entry per Check, in the order they ran
 }
 
-// Currently CODEOWNERS is the only thing we check in GitHub
-// so this path is very specific. TODO: /ghGetPATH/ can be dynamic
+// Files are read from GitHub at the API (ghDomain by default) + ghPreURI + repo + ghContentsPATH + path,
+// where the repository is "owner/name".
 const (
-	ghDomain  = "https://api.github.com"
-	ghPreURI  = "/repos/GhostGroup/"
-	ghGetPATH = "/contents/.github/CODEOWNERS"
+	ghDomain       = "https://api.github.com"
+	ghPreURI       = "/repos/"
+	ghContentsPATH = "/contents/"
+	ghBranchesPATH = "/branches/"
 )
 
+var GitHubNotFound = errors.New("not found in GitHub")
+
+// defaultGitHub is the GitHub client shared by the whole app.
+var defaultGitHub = NewGitHubClient(githubAPI())
+
+// githubAPI is the GitHub API to read from, ghDomain unless GH_API_URL is set,
+// e.g.: to use GitHub Enterprise or a fake GitHub.
+func githubAPI() string {
+	url := fillEnvVar("GH_API_URL")
+	if url == "ENOENT" {
+		return ghDomain
+	}
+	return strings.TrimSuffix(url, "/")
+}
+
+// ResolveRepo returns the first of the /candidates/ that exists in GitHub,
+// or an empty string if none do.
+// Only a missing repository moves on to the next candidate, any other error
+// says nothing about the Service and is returned so the run can be tried again.
+func ResolveRepo(gh *GitHubClient, candidates []string) (string, error) {
+	for _, repo := range candidates {
+		_, err := gh.Get(urlCat(gh.BaseURL, ghPreURI, repo))
+		if errors.Is(err, GitHubNotFound) {
+			slog.Warn("Repository Not Found", slog.String("Repo", repo))
+			continue
+		}
+		if err != nil {
+			slog.Error("Repository Unresolvable", slog.String("Repo", repo), slog.Any("Error", err))
+			return "", err
+		}
+		slog.Info("Repository Resolved", slog.String("Repo", repo))
+		return repo, nil
+	}
+	return "", nil
+}
+
 // urlCat is variadic, concatenating any set of strings into a URL.
 // It can be used to embed a dynamic string alongside static parts of a URI.
 // /u/ is a slice of strings used to build completeURL
@@ -64,66 +134,83 @@ func urlCat(u ...string) string {
 	return completeURL
 }
 
-// TestItem is returning a test result to ReadinessDisplay
-// Currently this represents the "Owner" test between Backstage and GitHub
-func (s *SvcTestDB) TestItem(svc string) *TestReturn {
-	// Check the owner field.
-	// Validation: If it's populated, return true.
-	// Verification: If it's populated with the correct string, return true.
-
-	var present, works bool
+// github is the client for GitHub-backed Checks.
+func (s *SvcTestDB) github() *GitHubClient {
+	if s.GitHub == nil {
+		return defaultGitHub
+	}
+	return s.GitHub
+}
 
-	// answer, err := Fetch(urlCat(ghDomain, ghPreURI, svc, ghGetPATH))
+// TestItems runs each Check in turn against this service.
+// Every Check shares this SvcTestDB, so a failed Check
+// decrements the same Score the next Check sees.
+func (s *SvcTestDB) TestItems(svc string) *VerifyResult {
+	s.Service = svc
 
-	// Get the actual value from CODEOWNERS in the matching GitHub repos
-	// This can take a map of URLs, but for now we only have one to give it.
-	target := urlCat(ghDomain, ghPreURI, svc, ghGetPATH)
-	urls := map[int]string{0: target}
-	answer, err := MultiFetch(urls)
-	if err != nil {
-		slog.Error("Cannot Fetch", slog.Any("Error", err))
+	checks := s.Checks
+	if checks == nil {
+		checks = RegisteredChecks()
 	}
+	checks = s.Policy.filter(checks)
 
-	// Strip off the CODEOWNERS formatting
-	reality := strings.TrimPrefix(strings.TrimSuffix(answer[0], suffixGH), prefixGH)
-
-	// Check the Owner for any WMService in Backstage
-	if s.Owner == "" {
-		// Validation has failed, the field is empty
-		present = false
-		s.Score--
-		slog.Warn("Empty Field", slog.String("Owner", s.Owner))
-		// Verification automatically fails
-		s.Score--
-		slog.Info("New Adjustment", slog.Int("Score", s.Score))
-	} else {
-		// Validation succeeds!
-		present = true
-		// Now check if it is equal to the retrieved source of truth
-		if s.Owner != reality {
-			// Verification has failed
-			works = false
-			slog.Warn("Unequal Field", slog.String("Owner", s.Owner), slog.String("Reality", reality))
-			s.Score--
-			slog.Info("New Adjustment", slog.Int("Score", s.Score))
-		} else {
-			// Verification succeeds!
-			works = true
-			slog.Info("Matching Field", slog.String("Owner", s.Owner), slog.String("Reality", reality), slog.Int("Score", s.Score))
+	result := &VerifyResult{Service: svc, Kind: s.Kind, System: s.System, Datetime: s.Datetime, Principles: PrincipleScores{}}
+	if s.Policy != nil {
+		result.Policy = s.Policy.Name
+	}
+	for _, c := range checks {
+		// Once GitHub is out, the rest of the answers would be about GitHub, not the Service
+		if s.outage != nil {
+			break
 		}
+		info := c.Info()
+
+		// Whatever the Check takes from the Score
+		// is also taken from each Principle it covers.
+		before := s.Score
+		tr := c.TestItem(s)
+		tr.ID = info.ID
+		tr.Principles = info.Principles
+		tr.Penalty = before - s.Score
+		tr.Required = s.Policy.required(info)
+		result.Principles.deduct(info.Principles, tr.Penalty)
+		result.Results = append(result.Results, tr)
+		slog.Debug("Check Complete", slog.String("Service", svc), slog.String("ID", info.ID), slog.Bool("Works", tr.Works))
 	}
+	result.Score = s.Score
+	result.Status = readinessOf(result.Results)
 
-	// This will be included in the API return value
-	return &TestReturn{Present: present, Owner: s.Owner, Reality: reality, Works: works, Score: s.Score}
+	return result
+}
+
+// unavailable records /err/ as the outage if it says GitHub couldn't answer,
+// i.e.: it ran out of rate limit or kept failing, rather than saying anything about the Service.
+// Check it before scoring a GitHub error: the run is tried again rather than stored.
+func (s *SvcTestDB) unavailable(err error) bool {
+	if !errors.Is(err, GitHubRateLimited) && !errors.Is(err, errGitHubTransient) {
+		return false
+	}
+	if s.outage == nil {
+		slog.Warn("GitHub Unavailable", slog.String("Service", s.Service), slog.Any("Error", err))
+		s.outage = err
+	}
+	return true
+}
+
+// Outage is why GitHub couldn't answer during TestItems, nil if it always did.
+// A result with an Outage is incomplete and shouldn't be stored.
+func (s *SvcTestDB) Outage() error {
+	return s.outage
 }
 
 // ReadinessDisplay takes the data and runs queries for processing and presentation.
 // The first arg /i/ is the catalog with its data.
 // The second is which service is being tested.
 // The third is where this output goes.
-func ReadinessDisplay(i SvcTest, service string, w io.Writer) error {
-	// Our first test is just to verify that the Codeowners field is populated in Backstage.
-	returnedTest := i.TestItem(service)
+// The combined result is handed back for storage.
+func ReadinessDisplay(i SvcTest, service string, w io.Writer) (*VerifyResult, error) {
+	// Run every registered check and collect the answers.
+	returnedTest := i.TestItems(service)
 	returnOut, err := json.Marshal(returnedTest)
 	if err != nil {
 		slog.Error("Failed to marshal struct to JSON", slog.Any("Error", err))
@@ -134,7 +221,7 @@ func ReadinessDisplay(i SvcTest, service string, w io.Writer) error {
 	if err != nil {
 		slog.Error("Failed to print JSON to Writer", slog.Any("Error", err))
 	}
-	return err
+	return returnedTest, err
 }
 
 // MultiFetch is ConfiguredFetch for multiple urls in a []string
@@ -164,55 +251,7 @@ func MultiFetch(urls map[int]string) ([]string, error) {
 	return results, nil
 }
 
-// getGitHub should take the url and a pointer to the results
-// then update the pointer and return only an error
+// getGitHub fetches a single URL with the shared GitHub client.
 func getGitHub(currURL string) (string, error) {
-	// Grab GH_TOKEN from the environment
-	// if there's no EnvVar, log an error and go no further
-	envVar := "GH_TOKEN"
-	token := fillEnvVar(envVar)
-	if token == "ENOENT" {
-		slog.Error("Environment Variable not set", slog.String("Key", envVar), slog.String("Value", token))
-		return token, nil
-	}
-
-	// Build the authHeader with the new token
-	authHeader := "Bearer " + token + ""
-
-	// Create a new HTTP request object
-	// This will be passed to a new HTTP client below.
-	req, err := http.NewRequest(http.MethodGet, currURL, nil)
-	if err != nil {
-		slog.Error("Could not create http client request", slog.String("URL", currURL), slog.Any("Error", err))
-		return "", err
-	}
-
-	// Add Auth headers to the object.
-	req.Header.Add("Accept", "application/vnd.github.raw+json")
-	req.Header.Add("Authorization", authHeader)
-
-	// Create a new Client pointer with a configured timeout
-	client := &http.Client{Timeout: webTimeout}
-
-	// Perform the actual Get.
-	r, err := client.Do(req)
-	if err != nil {
-		slog.Error("Could not reach service", slog.String("URL", currURL), slog.Any("Error", err))
-		r.Body.Close()
-		return "", err
-	}
-	defer r.Body.Close()
-
-	if r.StatusCode != http.StatusOK {
-		slog.Error("Non-200 Status", slog.String("URL", currURL), slog.Any("Status", r.StatusCode))
-		return "", errors.New("non 200 Status")
-	}
-
-	body, err := io.ReadAll(r.Body)
-	if err != nil {
-		slog.Error("Could not read value at", slog.String("URL", currURL), slog.Any("Error", err))
-	}
-
-	bodyString := string(body)
-	return bodyString, err
+	return defaultGitHub.Get(currURL)
 }
@@ -5,6 +5,7 @@ import (
 	"log"
 	"net/http"
 	"net/http/httptest"
+	"strconv"
 	"testing"
 	"time"
 
@@ -20,14 +21,22 @@ type mockSvcTestDB struct {
 }
 
 // These 'false' values are what comes through the mock call
-// func (db *mockSvcTestDB) TestItem(svc string) (bool, string, string, bool) {
-func (db *mockSvcTestDB) TestItem(svc string) *TestReturn {
-	return &TestReturn{
-		Present: true,
-		Owner:   "mock-admin-group",
-		Reality: "mock-developer-group",
-		Works:   false,
-		Score:   100}
+func (db *mockSvcTestDB) TestItems(svc string) *VerifyResult {
+	return &VerifyResult{
+		Service:  svc,
+		Datetime: db.Datetime,
+		Score:    100,
+		Status:   Degraded,
+		Principles: PrincipleScores{
+			Reliability: 100,
+		},
+		Results: []*TestReturn{{
+			Present: true,
+			Owner:   "mock-admin-group",
+			Reality: "mock-developer-group",
+			Works:   false,
+			Score:   100}},
+	}
 }
 
 // Is Readiness Display correctly writing results?
@@ -37,10 +46,10 @@ func TestReadinessDisplay(t *testing.T) {
 	mockRD := &mockSvcTestDB{Service: service, Datetime: 1724367242, Owner: "code-owners-admin", Score: 0}
 
 	t.Run("Is Readiness Display correctly writing results?", func(t *testing.T) {
-		// ReadinessDisplay calls TestItem, which needs to send us more data
-		err := ReadinessDisplay(mockRD, service, &buffer)
+		// ReadinessDisplay calls TestItems, which needs to send us more data
+		_, err := ReadinessDisplay(mockRD, service, &buffer)
 		got := buffer.String()
-		want := "{\"Present\":true,\"Owner\":\"mock-admin-group\",\"Reality\":\"mock-developer-group\",\"Works\":false,\"Score\":100}"
+		want := "{\"Service\":\"admin\",\"Datetime\":1724367242,\"Score\":100,\"Status\":\"Degraded\",\"Principles\":{\"reliability\":100},\"Results\":[{\"Present\":true,\"Owner\":\"mock-admin-group\",\"Reality\":\"mock-developer-group\",\"Works\":false,\"Score\":100}]}"
 
 		// What we're comparing is the buffer string, not the structs.
 		if diff := cmp.Diff(got, want); diff != "" {
@@ -51,19 +60,58 @@ func TestReadinessDisplay(t *testing.T) {
 	})
 }
 
-// This is an integration test to check that a GitHub URL is reachable.
-// getGitHub checks for the token in the GH_TOKEN EnvVar
+// getGitHub reads files with the shared client, here pointed at the fake GitHub.
 func TestGetGitHub(t *testing.T) {
-	t.Run("Integration: GitHub auth is working", func(t *testing.T) {
-		svc := "admin"
-		var got string
-		want := "* @GhostGroup/js-developers\n"
-		url := ghDomain + ghPreURI + svc + ghGetPATH
+	gh, fake := newFakeGitHub(t, fakeGitHubFixtures)
+	withDefaultGitHub(t, gh)
+	svc := "admin"
+	url := gh.BaseURL + ghPreURI + ghOrg + "/" + svc + ghContentsPATH + ".github/CODEOWNERS"
+
+	t.Run("GitHub auth is working", func(t *testing.T) {
 		got, err := getGitHub(url)
+		want := "* @GhostGroup/js-developers\n"
 
 		assertError(t, err, nil)
 		assertString(t, got, want)
 	})
+
+	t.Run("An unchanged file doesn't use the rate limit", func(t *testing.T) {
+		before := gh.RateLimit().Remaining
+		got, err := getGitHub(url)
+
+		assertError(t, err, nil)
+		assertString(t, got, "* @GhostGroup/js-developers\n")
+		if after := gh.RateLimit().Remaining; after != before {
+			t.Errorf("got %d remaining want %d", after, before)
+		}
+		if requests := len(fake.Requests()); requests != 2 {
+			t.Errorf("got %d requests want 2", requests)
+		}
+	})
+
+	t.Run("Unknown repositories and files are not found", func(t *testing.T) {
+		_, err := getGitHub(gh.BaseURL + ghPreURI + ghOrg + "/Revolution")
+		assertError(t, err, GitHubNotFound)
+
+		_, err = getGitHub(gh.BaseURL + ghPreURI + ghOrg + "/" + svc + ghContentsPATH + "CODEOWNERS")
+		assertError(t, err, GitHubNotFound)
+
+		_, err = getGitHub(gh.BaseURL + ghPreURI + ghOrg + "/" + svc + ghContentsPATH + "../weedmaps/CODEOWNERS")
+		assertError(t, err, GitHubNotFound)
+	})
+}
+
+// Missing files are told apart from other failures
+func TestGetGitHubNotFound(t *testing.T) {
+	t.Setenv("GH_TOKEN", "test-token")
+	missing := httptest.NewServer(http.NotFoundHandler())
+	defer missing.Close()
+
+	_, err := getGitHub(missing.URL + "/repos/GhostGroup/admin/contents/CODEOWNERS")
+	assertError(t, err, GitHubNotFound)
+
+	_, err = MultiFetch(map[int]string{0: missing.URL})
+	assertError(t, err, GitHubNotFound)
 }
 
 func TestMultiFetch(t *testing.T) {
@@ -139,3 +187,45 @@ func makeMockWebServ(delay time.Duration) *httptest.Server {
 		}
 	}))
 }
+
+func TestResolveRepo(t *testing.T) {
+	t.Run("moves past a repository that doesn't exist", func(t *testing.T) {
+		gh, fake, _ := newTestGitHubClient(t,
+			answer(http.StatusNotFound, "Not Found"),
+			answer(http.StatusOK, "{}"),
+		)
+
+		got, err := ResolveRepo(gh, []string{"GhostGroup/retired", "GhostGroup/admin"})
+		assertNoError(t, err)
+		assertString(t, got, "GhostGroup/admin")
+		assertIDEquals(t, len(fake.requests), 2)
+	})
+
+	t.Run("none of the candidates exist", func(t *testing.T) {
+		gh, _, _ := newTestGitHubClient(t, answer(http.StatusNotFound, "Not Found"))
+
+		got, err := ResolveRepo(gh, []string{"GhostGroup/retired", "GhostGroup/gone"})
+		assertNoError(t, err)
+		assertString(t, got, "")
+	})
+
+	t.Run("an outage isn't a missing repository", func(t *testing.T) {
+		gh, _, _ := newTestGitHubClient(t, answer(http.StatusServiceUnavailable, ""))
+
+		got, err := ResolveRepo(gh, []string{"GhostGroup/admin", "GhostGroup/retired"})
+		assertError(t, err, errGitHubTransient)
+		assertString(t, got, "")
+	})
+}
+
+// An outage stops the run, the rest of the answers would only be about GitHub
+func TestTestItemsOutage(t *testing.T) {
+	gh, fake, _ := newTestGitHubClient(t, answer(http.StatusForbidden, "", "X-RateLimit-Remaining", "0", "X-RateLimit-Reset", strconv.FormatInt(time.Now().Add(time.Hour).Unix(), 10)))
+	s := &SvcTestDB{Owner: "code-owners-admin", Repo: "GhostGroup/admin", GitHub: gh, Score: 100, Checks: []Check{codeownersCheck{}, ownerCheck{}}}
+
+	got := s.TestItems("admin")
+	assertError(t, s.Outage(), GitHubRateLimited)
+	assertIDEquals(t, len(got.Results), 1)
+	assertIDEquals(t, got.Score, 100)
+	assertIDEquals(t, len(fake.requests), 1)
+}
@@ -0,0 +1,236 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+)
+
+const (
+	ghMaxRetries  = 3                      // Retries for a 5xx or an unreachable GitHub
+	ghBackoff     = 500 * time.Millisecond // First wait between retries, doubled each time
+	ghMaxRateWait = time.Minute            // Longest we'll sleep for the rate limit before giving up on a request
+	ghAPIVersion  = "2022-11-28"
+)
+
+var GitHubRateLimited = errors.New("GitHub rate limit reached")
+
+// GitHubForbidden is a 403 that isn't the rate limit, i.e.: the token can't read it.
+var GitHubForbidden = errors.New("forbidden by GitHub")
+
+// RateLimit is the last rate limit GitHub reported.
+type RateLimit struct {
+	Limit     int
+	Remaining int
+	Reset     time.Time
+}
+
+// ghCached is a response kept for conditional requests.
+type ghCached struct {
+	etag string
+	body string
+}
+
+// GitHubClient reads from the GitHub API.
+// One client is shared by every Check and every job,
+// so they share its connections, its ETag cache and what it knows about the rate limit.
+type GitHubClient struct {
+	BaseURL string // e.g.: https://api.github.com
+	Token   func() (string, error)
+
+	http  *http.Client
+	sleep func(time.Duration)
+
+	mu    sync.Mutex
+	rate  RateLimit
+	cache map[string]ghCached
+}
+
+// NewGitHubClient Constructor
+// The token is read from GH_TOKEN on every request,
+// replace Token to authenticate another way, e.g.: with an AppTokenSource.
+func NewGitHubClient(baseURL string) *GitHubClient {
+	return &GitHubClient{
+		BaseURL: baseURL,
+		Token:   envToken,
+		http:    &http.Client{Timeout: webTimeout},
+		sleep:   time.Sleep,
+		cache:   make(map[string]ghCached),
+	}
+}
+
+// envToken reads a Personal Access Token from GH_TOKEN.
+func envToken() (string, error) {
+	envVar := "GH_TOKEN"
+	token := fillEnvVar(envVar)
+	if token == "ENOENT" {
+		return "", fmt.Errorf("environment variable %s not set", envVar)
+	}
+	return token, nil
+}
+
+// RateLimit returns the rate limit from the latest response.
+func (g *GitHubClient) RateLimit() RateLimit {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	return g.rate
+}
+
+// Get fetches /url/ and returns the body.
+// An unchanged file is answered from the cache, which doesn't count against the rate limit.
+// A missing file returns GitHubNotFound, and running out of rate limit for longer than
+// ghMaxRateWait returns GitHubRateLimited so the work can be tried again later.
+func (g *GitHubClient) Get(url string) (string, error) {
+	backoff := ghBackoff
+	for attempt := 0; ; attempt++ {
+		if err := g.waitForRateLimit(); err != nil {
+			return "", err
+		}
+
+		body, retryAfter, err := g.get(url)
+		if retryAfter > 0 {
+			// GitHub asked us to slow down
+			if retryAfter > ghMaxRateWait || attempt >= ghMaxRetries {
+				return "", fmt.Errorf("%w, retry after %s", GitHubRateLimited, retryAfter)
+			}
+			slog.Warn("GitHub Rate Limited", slog.String("URL", url), slog.Duration("RetryAfter", retryAfter))
+			g.sleep(retryAfter)
+			continue
+		}
+		if !errors.Is(err, errGitHubTransient) || attempt >= ghMaxRetries {
+			return body, err
+		}
+
+		slog.Warn("GitHub Retry", slog.String("URL", url), slog.Int("Attempt", attempt+1), slog.Any("Error", err))
+		g.sleep(backoff)
+		backoff *= 2
+	}
+}
+
+var errGitHubTransient = errors.New("transient GitHub error")
+
+// get makes a single request.
+// A non-zero duration means GitHub asked for the request to be retried after it.
+func (g *GitHubClient) get(url string) (string, time.Duration, error) {
+	req, err := http.NewRequest(http.MethodGet, url, nil)
+	if err != nil {
+		slog.Error("Could not create http client request", slog.String("URL", url), slog.Any("Error", err))
+		return "", 0, err
+	}
+	req.Header.Add("Accept", "application/vnd.github.raw+json")
+	req.Header.Add("X-GitHub-Api-Version", ghAPIVersion)
+
+	// Without a token the request still goes, with a much lower rate limit
+	if token, err := g.Token(); err != nil {
+		slog.Warn("GitHub Unauthenticated", slog.Any("Error", err))
+	} else {
+		req.Header.Add("Authorization", "Bearer "+token)
+	}
+
+	g.mu.Lock()
+	cached, ok := g.cache[url]
+	g.mu.Unlock()
+	if ok {
+		req.Header.Add("If-None-Match", cached.etag)
+	}
+
+	r, err := g.http.Do(req)
+	if err != nil {
+		slog.Error("Could not reach service", slog.String("URL", url), slog.Any("Error", err))
+		return "", 0, fmt.Errorf("%w: %v", errGitHubTransient, err)
+	}
+	defer r.Body.Close()
+	g.updateRateLimit(r.Header)
+
+	switch {
+	case r.StatusCode == http.StatusNotModified && ok:
+		slog.Debug("Not Modified", slog.String("URL", url))
+		return cached.body, 0, nil
+	case r.StatusCode == http.StatusNotFound:
+		// Missing files are expected while probing, so they get their own error
+		slog.Debug("Not Found", slog.String("URL", url))
+		return "", 0, GitHubNotFound
+	case r.StatusCode == http.StatusForbidden || r.StatusCode == http.StatusTooManyRequests:
+		if wait := g.rateLimitWait(r.Header); wait > 0 {
+			return "", wait, nil
+		}
+		if r.StatusCode == http.StatusForbidden {
+			slog.Error("Forbidden", slog.String("URL", url))
+			return "", 0, GitHubForbidden
+		}
+	case r.StatusCode >= http.StatusInternalServerError:
+		slog.Error("Non-200 Status", slog.String("URL", url), slog.Any("Status", r.StatusCode))
+		return "", 0, fmt.Errorf("%w: status %d", errGitHubTransient, r.StatusCode)
+	}
+
+	if r.StatusCode != http.StatusOK {
+		slog.Error("Non-200 Status", slog.String("URL", url), slog.Any("Status", r.StatusCode))
+		return "", 0, fmt.Errorf("non 200 Status: %d", r.StatusCode)
+	}
+
+	body, err := io.ReadAll(r.Body)
+	if err != nil {
+		slog.Error("Could not read value at", slog.String("URL", url), slog.Any("Error", err))
+		return "", 0, err
+	}
+
+	if etag := r.Header.Get("ETag"); etag != "" {
+		g.mu.Lock()
+		g.cache[url] = ghCached{etag: etag, body: string(body)}
+		g.mu.Unlock()
+	}
+	return string(body), 0, nil
+}
+
+// updateRateLimit keeps the rate limit GitHub reports on every response.
+func (g *GitHubClient) updateRateLimit(h http.Header) {
+	remaining, err := strconv.Atoi(h.Get("X-RateLimit-Remaining"))
+	if err != nil {
+		return
+	}
+	limit, _ := strconv.Atoi(h.Get("X-RateLimit-Limit"))
+	reset, _ := strconv.ParseInt(h.Get("X-RateLimit-Reset"), 10, 64)
+
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	g.rate = RateLimit{Limit: limit, Remaining: remaining, Reset: time.Unix(reset, 0)}
+}
+
+// rateLimitWait reads how long a rate limited response asks us to wait,
+// zero if the response isn't about the rate limit.
+func (g *GitHubClient) rateLimitWait(h http.Header) time.Duration {
+	// Secondary rate limits say when to come back
+	if seconds, err := strconv.Atoi(h.Get("Retry-After")); err == nil {
+		return time.Duration(seconds) * time.Second
+	}
+	if h.Get("X-RateLimit-Remaining") == "0" {
+		return max(time.Until(g.RateLimit().Reset), time.Second)
+	}
+	return 0
+}
+
+// waitForRateLimit sleeps until the rate limit resets if it's used up,
+// or returns GitHubRateLimited if that's too long to wait.
+func (g *GitHubClient) waitForRateLimit() error {
+	rate := g.RateLimit()
+	if rate.Limit == 0 || rate.Remaining > 0 {
+		return nil
+	}
+
+	wait := time.Until(rate.Reset)
+	if wait <= 0 {
+		return nil
+	}
+	if wait > ghMaxRateWait {
+		return fmt.Errorf("%w until %s", GitHubRateLimited, rate.Reset.Format(time.RFC3339))
+	}
+
+	slog.Warn("GitHub Rate Limit Wait", slog.Duration("Wait", wait))
+	g.sleep(wait)
+	return nil
+}
@@ -0,0 +1,205 @@
+package main
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+// fakeGitHub answers each request with the next handler in line, and counts requests.
+type fakeGitHub struct {
+	mu       sync.Mutex
+	answers  []http.HandlerFunc
+	requests []*http.Request
+}
+
+func (f *fakeGitHub) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.requests = append(f.requests, r)
+	next := f.answers[0]
+	if len(f.answers) > 1 {
+		f.answers = f.answers[1:]
+	}
+	next(w, r)
+}
+
+// newTestGitHubClient points a client at a fake GitHub and records its sleeps instead of taking them.
+func newTestGitHubClient(t *testing.T, answers ...http.HandlerFunc) (*GitHubClient, *fakeGitHub, *[]time.Duration) {
+	t.Helper()
+
+	fake := &fakeGitHub{answers: answers}
+	server := httptest.NewServer(fake)
+	t.Cleanup(server.Close)
+
+	var slept []time.Duration
+	gh := NewGitHubClient(server.URL)
+	gh.Token = func() (string, error) { return "test-token", nil }
+	gh.sleep = func(d time.Duration) { slept = append(slept, d) }
+
+	return gh, fake, &slept
+}
+
+func answer(status int, body string, headers ...string) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		for i := 0; i+1 < len(headers); i += 2 {
+			w.Header().Set(headers[i], headers[i+1])
+		}
+		w.WriteHeader(status)
+		fmt.Fprint(w, body)
+	}
+}
+
+func TestGitHubClient(t *testing.T) {
+	t.Run("authenticates and asks for raw files", func(t *testing.T) {
+		gh, fake, _ := newTestGitHubClient(t, answer(http.StatusOK, "* @GhostGroup/code-owners-admin\n"))
+
+		got, err := gh.Get(gh.BaseURL + "/repos/GhostGroup/admin/contents/CODEOWNERS")
+		assertNoError(t, err)
+		assertString(t, got, "* @GhostGroup/code-owners-admin\n")
+
+		r := fake.requests[0]
+		assertString(t, r.Header.Get("Authorization"), "Bearer test-token")
+		assertString(t, r.Header.Get("Accept"), "application/vnd.github.raw+json")
+	})
+
+	t.Run("goes unauthenticated without a token", func(t *testing.T) {
+		gh, fake, _ := newTestGitHubClient(t, answer(http.StatusOK, "ok"))
+		gh.Token = envToken
+		t.Setenv("GH_TOKEN", "")
+
+		_, err := gh.Get(gh.BaseURL)
+		assertNoError(t, err)
+		assertString(t, fake.requests[0].Header.Get("Authorization"), "")
+	})
+
+	t.Run("unchanged files come from the cache", func(t *testing.T) {
+		gh, fake, _ := newTestGitHubClient(t,
+			answer(http.StatusOK, "first", "ETag", `"abc"`),
+			answer(http.StatusNotModified, ""),
+		)
+
+		first, err := gh.Get(gh.BaseURL + "/readme")
+		assertNoError(t, err)
+		second, err := gh.Get(gh.BaseURL + "/readme")
+		assertNoError(t, err)
+
+		assertString(t, second, first)
+		assertString(t, fake.requests[0].Header.Get("If-None-Match"), "")
+		assertString(t, fake.requests[1].Header.Get("If-None-Match"), `"abc"`)
+	})
+
+	t.Run("5xx is retried with backoff", func(t *testing.T) {
+		gh, fake, slept := newTestGitHubClient(t,
+			answer(http.StatusBadGateway, ""),
+			answer(http.StatusServiceUnavailable, ""),
+			answer(http.StatusOK, "ok"),
+		)
+
+		got, err := gh.Get(gh.BaseURL)
+		assertNoError(t, err)
+		assertString(t, got, "ok")
+
+		assertIDEquals(t, len(fake.requests), 3)
+		if diff := cmp.Diff(*slept, []time.Duration{ghBackoff, 2 * ghBackoff}); diff != "" {
+			t.Error(diff)
+		}
+	})
+
+	t.Run("5xx gives up after the retries", func(t *testing.T) {
+		gh, fake, _ := newTestGitHubClient(t, answer(http.StatusInternalServerError, ""))
+
+		_, err := gh.Get(gh.BaseURL)
+		if err == nil {
+			t.Fatal("expected an error")
+		}
+		assertIDEquals(t, len(fake.requests), ghMaxRetries+1)
+	})
+
+	t.Run("missing files are not retried", func(t *testing.T) {
+		gh, fake, _ := newTestGitHubClient(t, answer(http.StatusNotFound, ""))
+
+		_, err := gh.Get(gh.BaseURL)
+		assertError(t, err, GitHubNotFound)
+		assertIDEquals(t, len(fake.requests), 1)
+	})
+
+	t.Run("forbidden isn't the rate limit", func(t *testing.T) {
+		gh, fake, slept := newTestGitHubClient(t, answer(http.StatusForbidden, "", "X-RateLimit-Remaining", "4999"))
+
+		_, err := gh.Get(gh.BaseURL)
+		assertError(t, err, GitHubForbidden)
+		assertIDEquals(t, len(fake.requests), 1)
+		assertIDEquals(t, len(*slept), 0)
+	})
+
+	t.Run("rate limit headers are kept", func(t *testing.T) {
+		reset := time.Now().Add(time.Hour).Unix()
+		gh, _, _ := newTestGitHubClient(t, answer(http.StatusOK, "ok",
+			"X-RateLimit-Limit", "5000",
+			"X-RateLimit-Remaining", "4999",
+			"X-RateLimit-Reset", strconv.FormatInt(reset, 10),
+		))
+
+		gh.Get(gh.BaseURL)
+
+		got := gh.RateLimit()
+		want := RateLimit{Limit: 5000, Remaining: 4999, Reset: time.Unix(reset, 0)}
+		if got != want {
+			t.Errorf("got %+v want %+v", got, want)
+		}
+	})
+
+	t.Run("waits for a rate limit that resets soon", func(t *testing.T) {
+		reset := time.Now().Add(30 * time.Second).Unix()
+		gh, fake, slept := newTestGitHubClient(t,
+			answer(http.StatusOK, "ok", "X-RateLimit-Limit", "5000", "X-RateLimit-Remaining", "0", "X-RateLimit-Reset", strconv.FormatInt(reset, 10)),
+			answer(http.StatusOK, "ok"),
+		)
+
+		gh.Get(gh.BaseURL)
+		_, err := gh.Get(gh.BaseURL)
+		assertNoError(t, err)
+
+		assertIDEquals(t, len(fake.requests), 2)
+		if len(*slept) != 1 || (*slept)[0] <= 0 || (*slept)[0] > 30*time.Second {
+			t.Errorf("got sleeps %v want one wait for the reset", *slept)
+		}
+	})
+
+	t.Run("defers work when the rate limit resets much later", func(t *testing.T) {
+		reset := time.Now().Add(time.Hour).Unix()
+		gh, fake, _ := newTestGitHubClient(t,
+			answer(http.StatusOK, "ok", "X-RateLimit-Limit", "5000", "X-RateLimit-Remaining", "0", "X-RateLimit-Reset", strconv.FormatInt(reset, 10)),
+		)
+
+		gh.Get(gh.BaseURL)
+		_, err := gh.Get(gh.BaseURL)
+		assertError(t, err, GitHubRateLimited)
+
+		// The second request never went out
+		assertIDEquals(t, len(fake.requests), 1)
+	})
+
+	t.Run("secondary rate limits are retried after waiting", func(t *testing.T) {
+		gh, fake, slept := newTestGitHubClient(t,
+			answer(http.StatusTooManyRequests, "", "Retry-After", "2"),
+			answer(http.StatusOK, "ok"),
+		)
+
+		got, err := gh.Get(gh.BaseURL)
+		assertNoError(t, err)
+		assertString(t, got, "ok")
+
+		assertIDEquals(t, len(fake.requests), 2)
+		if diff := cmp.Diff(*slept, []time.Duration{2 * time.Second}); diff != "" {
+			t.Error(diff)
+		}
+	})
+}
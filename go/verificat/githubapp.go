@@ -0,0 +1,193 @@
+package main
+
+import (
+	"crypto"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"os"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	appJWTLifetime  = 9 * time.Minute // GitHub allows at most 10
+	appClockSkew    = time.Minute     // JWTs are issued this far in the past in case our clock is ahead
+	appTokenRefresh = 5 * time.Minute // Installation tokens are replaced this long before they expire
+)
+
+// AppTokenSource authenticates as a GitHub App installation.
+// The App's private key signs a short-lived JWT, which is exchanged for an installation token.
+// The installation token lasts an hour and is cached until shortly before it expires.
+type AppTokenSource struct {
+	BaseURL        string // e.g.: https://api.github.com
+	AppID          string
+	InstallationID string
+
+	key  *rsa.PrivateKey
+	http *http.Client
+	now  func() time.Time
+
+	mu      sync.Mutex
+	token   string
+	expires time.Time
+}
+
+// NewAppTokenSource Constructor
+// /pemKey/ is the private key downloaded from the App's settings.
+func NewAppTokenSource(baseURL, appID, installationID string, pemKey []byte) (*AppTokenSource, error) {
+	key, err := parseAppKey(pemKey)
+	if err != nil {
+		return nil, err
+	}
+
+	return &AppTokenSource{
+		BaseURL:        baseURL,
+		AppID:          appID,
+		InstallationID: installationID,
+		key:            key,
+		http:           &http.Client{Timeout: webTimeout},
+		now:            time.Now,
+	}, nil
+}
+
+// AppTokenSourceFromEnv builds an AppTokenSource from GH_APP_ID, GH_APP_INSTALLATION_ID and GH_APP_PRIVATE_KEY.
+// The key can be the PEM itself or a path to it.
+// If none are set it returns nil, and GH_TOKEN is used instead.
+func AppTokenSourceFromEnv(baseURL string) (*AppTokenSource, error) {
+	appID := fillEnvVar("GH_APP_ID")
+	installationID := fillEnvVar("GH_APP_INSTALLATION_ID")
+	key := fillEnvVar("GH_APP_PRIVATE_KEY")
+
+	set := 0
+	for _, v := range []string{appID, installationID, key} {
+		if v != "ENOENT" {
+			set++
+		}
+	}
+	switch set {
+	case 0:
+		return nil, nil
+	case 3:
+	default:
+		return nil, errors.New("GH_APP_ID, GH_APP_INSTALLATION_ID and GH_APP_PRIVATE_KEY must all be set")
+	}
+
+	pemKey := []byte(key)
+	if !strings.HasPrefix(strings.TrimSpace(key), "-----BEGIN") {
+		var err error
+		if pemKey, err = os.ReadFile(key); err != nil {
+			return nil, fmt.Errorf("problem reading GH_APP_PRIVATE_KEY, %v", err)
+		}
+	}
+
+	return NewAppTokenSource(baseURL, appID, installationID, pemKey)
+}
+
+// parseAppKey reads an RSA private key in either PKCS#1 (what GitHub hands out) or PKCS#8.
+func parseAppKey(pemKey []byte) (*rsa.PrivateKey, error) {
+	block, _ := pem.Decode(pemKey)
+	if block == nil {
+		return nil, errors.New("GitHub App private key is not PEM")
+	}
+
+	if key, err := x509.ParsePKCS1PrivateKey(block.Bytes); err == nil {
+		return key, nil
+	}
+	parsed, err := x509.ParsePKCS8PrivateKey(block.Bytes)
+	if err != nil {
+		return nil, fmt.Errorf("problem parsing GitHub App private key, %v", err)
+	}
+	key, ok := parsed.(*rsa.PrivateKey)
+	if !ok {
+		return nil, errors.New("GitHub App private key is not RSA")
+	}
+	return key, nil
+}
+
+// Token returns a current installation token, fetching a new one when it's about to expire.
+// This is a GitHubClient.Token.
+func (a *AppTokenSource) Token() (string, error) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	if a.token != "" && a.now().Add(appTokenRefresh).Before(a.expires) {
+		return a.token, nil
+	}
+
+	token, expires, err := a.installationToken()
+	if err != nil {
+		slog.Error("GitHub App Token Failed", slog.String("AppID", a.AppID), slog.Any("Error", err))
+		return "", err
+	}
+	a.token, a.expires = token, expires
+	slog.Info("GitHub App Token Refreshed", slog.String("AppID", a.AppID), slog.Time("Expires", expires))
+	return a.token, nil
+}
+
+// installationToken exchanges a fresh JWT for an installation token.
+func (a *AppTokenSource) installationToken() (string, time.Time, error) {
+	jwt, err := a.jwt()
+	if err != nil {
+		return "", time.Time{}, err
+	}
+
+	url := urlCat(a.BaseURL, "/app/installations/", a.InstallationID, "/access_tokens")
+	req, err := http.NewRequest(http.MethodPost, url, nil)
+	if err != nil {
+		return "", time.Time{}, err
+	}
+	req.Header.Add("Accept", "application/vnd.github+json")
+	req.Header.Add("Authorization", "Bearer "+jwt)
+	req.Header.Add("X-GitHub-Api-Version", ghAPIVersion)
+
+	r, err := a.http.Do(req)
+	if err != nil {
+		return "", time.Time{}, err
+	}
+	defer r.Body.Close()
+
+	if r.StatusCode != http.StatusCreated {
+		return "", time.Time{}, fmt.Errorf("installation token request returned %d", r.StatusCode)
+	}
+
+	var answer struct {
+		Token     string    `json:"token"`
+		ExpiresAt time.Time `json:"expires_at"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&answer); err != nil {
+		return "", time.Time{}, fmt.Errorf("problem reading installation token, %v", err)
+	}
+	return answer.Token, answer.ExpiresAt, nil
+}
+
+// jwt signs the App's identity with RS256.
+func (a *AppTokenSource) jwt() (string, error) {
+	now := a.now()
+	header, _ := json.Marshal(map[string]string{"alg": "RS256", "typ": "JWT"})
+	claims, _ := json.Marshal(map[string]any{
+		"iat": now.Add(-appClockSkew).Unix(),
+		"exp": now.Add(appJWTLifetime).Unix(),
+		"iss": a.AppID,
+	})
+
+	enc := base64.RawURLEncoding
+	unsigned := enc.EncodeToString(header) + "." + enc.EncodeToString(claims)
+
+	digest := sha256.Sum256([]byte(unsigned))
+	signature, err := rsa.SignPKCS1v15(rand.Reader, a.key, crypto.SHA256, digest[:])
+	if err != nil {
+		return "", fmt.Errorf("problem signing GitHub App JWT, %v", err)
+	}
+
+	return unsigned + "." + enc.EncodeToString(signature), nil
+}
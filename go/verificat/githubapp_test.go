@@ -0,0 +1,199 @@
+package main
+
+import (
+	"crypto"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// fakeAppGitHub hands out installation tokens to requests signed by /key/.
+type fakeAppGitHub struct {
+	t       *testing.T
+	key     *rsa.PublicKey
+	expires time.Time
+
+	mu     sync.Mutex
+	issued int
+}
+
+func (f *fakeAppGitHub) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if r.Method != http.MethodPost || r.URL.Path != "/app/installations/42/access_tokens" {
+		http.NotFound(w, r)
+		return
+	}
+
+	// Check the JWT the same way GitHub would
+	parts := strings.Split(strings.TrimPrefix(r.Header.Get("Authorization"), "Bearer "), ".")
+	if len(parts) != 3 {
+		http.Error(w, "bad JWT", http.StatusUnauthorized)
+		return
+	}
+	signature, _ := base64.RawURLEncoding.DecodeString(parts[2])
+	digest := sha256.Sum256([]byte(parts[0] + "." + parts[1]))
+	if err := rsa.VerifyPKCS1v15(f.key, crypto.SHA256, digest[:], signature); err != nil {
+		http.Error(w, "bad signature", http.StatusUnauthorized)
+		return
+	}
+	raw, _ := base64.RawURLEncoding.DecodeString(parts[1])
+	var claims struct {
+		Iss string `json:"iss"`
+		Iat int64  `json:"iat"`
+		Exp int64  `json:"exp"`
+	}
+	json.Unmarshal(raw, &claims)
+	if claims.Iss != "1234" || claims.Exp-claims.Iat > int64((10*time.Minute).Seconds()) {
+		http.Error(w, "bad claims", http.StatusUnauthorized)
+		return
+	}
+
+	f.issued++
+	w.WriteHeader(http.StatusCreated)
+	fmt.Fprintf(w, `{"token": "ghs_installation_%d", "expires_at": %q}`, f.issued, f.expires.Format(time.RFC3339))
+}
+
+func testAppKey(t *testing.T) (*rsa.PrivateKey, []byte) {
+	t.Helper()
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	assertNoError(t, err)
+	return key, pem.EncodeToMemory(&pem.Block{Type: "RSA PRIVATE KEY", Bytes: x509.MarshalPKCS1PrivateKey(key)})
+}
+
+func TestAppTokenSource(t *testing.T) {
+	key, pemKey := testAppKey(t)
+	now := time.Date(2024, 9, 1, 12, 0, 0, 0, time.UTC)
+
+	fake := &fakeAppGitHub{t: t, key: &key.PublicKey, expires: now.Add(time.Hour)}
+	server := httptest.NewServer(fake)
+	defer server.Close()
+
+	app, err := NewAppTokenSource(server.URL, "1234", "42", pemKey)
+	assertNoError(t, err)
+	app.now = func() time.Time { return now }
+
+	t.Run("exchanges a signed JWT for an installation token", func(t *testing.T) {
+		got, err := app.Token()
+		assertNoError(t, err)
+		assertString(t, got, "ghs_installation_1")
+	})
+
+	t.Run("caches the token until it's about to expire", func(t *testing.T) {
+		now = now.Add(30 * time.Minute)
+		got, _ := app.Token()
+		assertString(t, got, "ghs_installation_1")
+
+		now = now.Add(26 * time.Minute)
+		fake.expires = now.Add(time.Hour)
+		got, _ = app.Token()
+		assertString(t, got, "ghs_installation_2")
+	})
+
+	t.Run("the GitHub client sends the installation token", func(t *testing.T) {
+		var auth string
+		files := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			auth = r.Header.Get("Authorization")
+		}))
+		defer files.Close()
+
+		gh := NewGitHubClient(files.URL)
+		gh.Token = app.Token
+		gh.Get(files.URL)
+
+		assertString(t, auth, "Bearer ghs_installation_2")
+	})
+
+	t.Run("a key GitHub doesn't know is refused", func(t *testing.T) {
+		_, otherKey := testAppKey(t)
+		other, err := NewAppTokenSource(server.URL, "1234", "42", otherKey)
+		assertNoError(t, err)
+
+		if _, err := other.Token(); err == nil {
+			t.Error("expected an error for an unknown key")
+		}
+	})
+}
+
+func TestParseAppKey(t *testing.T) {
+	key, pkcs1 := testAppKey(t)
+
+	t.Run("PKCS#1", func(t *testing.T) {
+		got, err := parseAppKey(pkcs1)
+		assertNoError(t, err)
+		if !got.Equal(key) {
+			t.Error("parsed a different key")
+		}
+	})
+
+	t.Run("PKCS#8", func(t *testing.T) {
+		der, _ := x509.MarshalPKCS8PrivateKey(key)
+		got, err := parseAppKey(pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: der}))
+		assertNoError(t, err)
+		if !got.Equal(key) {
+			t.Error("parsed a different key")
+		}
+	})
+
+	t.Run("not PEM", func(t *testing.T) {
+		if _, err := parseAppKey([]byte("ghp_not_a_key")); err == nil {
+			t.Error("expected an error")
+		}
+	})
+}
+
+func TestAppTokenSourceFromEnv(t *testing.T) {
+	_, pemKey := testAppKey(t)
+
+	t.Run("absent means GH_TOKEN", func(t *testing.T) {
+		t.Setenv("GH_APP_ID", "")
+		t.Setenv("GH_APP_INSTALLATION_ID", "")
+		t.Setenv("GH_APP_PRIVATE_KEY", "")
+
+		app, err := AppTokenSourceFromEnv(ghDomain)
+		assertNoError(t, err)
+		if app != nil {
+			t.Error("expected no App")
+		}
+	})
+
+	t.Run("partly set is an error", func(t *testing.T) {
+		t.Setenv("GH_APP_ID", "1234")
+		t.Setenv("GH_APP_INSTALLATION_ID", "")
+		t.Setenv("GH_APP_PRIVATE_KEY", "")
+
+		if _, err := AppTokenSourceFromEnv(ghDomain); err == nil {
+			t.Error("expected an error")
+		}
+	})
+
+	t.Run("the key can be PEM or a path", func(t *testing.T) {
+		t.Setenv("GH_APP_ID", "1234")
+		t.Setenv("GH_APP_INSTALLATION_ID", "42")
+
+		t.Setenv("GH_APP_PRIVATE_KEY", string(pemKey))
+		app, err := AppTokenSourceFromEnv(ghDomain)
+		assertNoError(t, err)
+		assertString(t, app.InstallationID, "42")
+
+		path := filepath.Join(t.TempDir(), "app.pem")
+		assertNoError(t, os.WriteFile(path, pemKey, 0600))
+		t.Setenv("GH_APP_PRIVATE_KEY", path)
+		_, err = AppTokenSourceFromEnv(ghDomain)
+		assertNoError(t, err)
+	})
+}
@@ -40,6 +40,24 @@ func TestRender(t *testing.T) {
 		approvals.VerifyString(t, buf.String())
 	})
 
+	t.Run("renders the Systems as a tree", func(t *testing.T) {
+		var tree bytes.Buffer
+		systems := Almanac{
+			{Name: "core", Kind: KindSystem, LastID: 3, Score: 100, Status: Ready},
+			{Name: "component:core-braze", Kind: KindComponent, System: "core", LastID: 1, Score: 98, Status: NotReady},
+			{Name: "api:ad-server-api", Kind: KindAPI, System: "ad-server", LastID: 2, Score: 99, Status: Degraded},
+		}.Systems(nil)
+		for i := range systems {
+			RollupConfig{Aggregation: AggregateMin}.Apply(&systems[i])
+		}
+
+		web := &AlmanacWeb{Title: "Most Recent Almanac", Content: "SVG", Systems: systems}
+		if err := RenderWeb(&tree, web, tmpldir, targetDoc); err != nil {
+			t.Fatal(err)
+		}
+		approvals.VerifyString(t, tree.String())
+	})
+
 	t.Run("bad template location returns an error", func(t *testing.T) {
 		tmpldir = "something/*else.html"
 		if err := RenderWeb(&buf, aWeb, tmpldir, targetDoc); err == nil {
@@ -0,0 +1,241 @@
+package main
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"path"
+	"slices"
+	"strconv"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// helmPaths are the Helm values files looked for in the Service's repository.
+// HELM_VALUES_PATHS replaces them.
+var helmPaths = []string{"values.yaml", "chart/values.yaml", "helm/values.yaml", "deploy/values.yaml", "kube/values.yaml"}
+
+// HelmMapping is where a chart's values configure each readiness setting, as dotted paths.
+// The defaults are the values `helm create` scaffolds, HELM_MAPPING_FILE replaces any of them.
+type HelmMapping struct {
+	Replicas         string `yaml:"replicas"`         // A number
+	Autoscaling      string `yaml:"autoscaling"`      // A bool, whether the chart renders a HorizontalPodAutoscaler
+	MinReplicas      string `yaml:"minReplicas"`      // A number, the autoscaler's floor
+	MaxReplicas      string `yaml:"maxReplicas"`      // A number
+	Resources        string `yaml:"resources"`        // A map holding requests and limits
+	LivenessProbe    string `yaml:"livenessProbe"`    // A map
+	ReadinessProbe   string `yaml:"readinessProbe"`   // A map
+	DisruptionBudget string `yaml:"disruptionBudget"` // A map with enabled, minAvailable or maxUnavailable, or a bool
+}
+
+// defaultHelmMapping follows the values of a chart made with `helm create`.
+var defaultHelmMapping = HelmMapping{
+	Replicas:         "replicaCount",
+	Autoscaling:      "autoscaling.enabled",
+	MinReplicas:      "autoscaling.minReplicas",
+	MaxReplicas:      "autoscaling.maxReplicas",
+	Resources:        "resources",
+	LivenessProbe:    "livenessProbe",
+	ReadinessProbe:   "readinessProbe",
+	DisruptionBudget: "podDisruptionBudget",
+}
+
+// NewHelmMapping reads the HelmMapping from the YAML file at HELM_MAPPING_FILE,
+// e.g.: replicas: app.replicas
+// The paths it sets replace the defaults, the rest are kept. Unset, unreadable or naming a setting
+// that doesn't exist, the defaults are kept.
+func NewHelmMapping() HelmMapping {
+	file := fillEnvVar("HELM_MAPPING_FILE")
+	if file == "ENOENT" {
+		return defaultHelmMapping
+	}
+
+	raw, err := os.ReadFile(file)
+	if err == nil {
+		// A misspelt setting would otherwise be dropped without a word
+		mapping := defaultHelmMapping
+		dec := yaml.NewDecoder(bytes.NewReader(raw))
+		dec.KnownFields(true)
+		if err = dec.Decode(&mapping); err == nil || errors.Is(err, io.EOF) {
+			return mapping
+		}
+	}
+	slog.Error("Helm mapping could not be loaded", slog.String("Key", "HELM_MAPPING_FILE"), slog.String("Value", file), slog.Any("Error", err))
+	return defaultHelmMapping
+}
+
+// settings is which value configures each readiness setting, for the Findings to point at.
+func (hm HelmMapping) settings() map[string]string {
+	return map[string]string{
+		"replicas":            hm.Replicas,
+		"minReplicas":         hm.MinReplicas,
+		"resources":           hm.Resources,
+		"livenessProbe":       hm.LivenessProbe,
+		"readinessProbe":      hm.ReadinessProbe,
+		"podDisruptionBudget": hm.DisruptionBudget,
+	}
+}
+
+// lookupValue walks a dotted path down a values document, nil when any step is missing.
+func lookupValue(n *yaml.Node, dotted string) *yaml.Node {
+	if dotted == "" {
+		return nil
+	}
+	for _, key := range strings.Split(dotted, ".") {
+		if n == nil || n.Kind != yaml.MappingNode {
+			return nil
+		}
+		var next *yaml.Node
+		for i := 0; i+1 < len(n.Content); i += 2 {
+			if n.Content[i].Value == key {
+				next = n.Content[i+1]
+				break
+			}
+		}
+		n = next
+	}
+	return n
+}
+
+// isSet is true for a value that's more than null or an empty map, which is how charts switch things off.
+func isSet(n *yaml.Node) bool {
+	switch {
+	case n == nil:
+		return false
+	case n.Kind == yaml.MappingNode, n.Kind == yaml.SequenceNode:
+		return len(n.Content) > 0
+	default:
+		return n.Tag != "!!null" && n.Value != ""
+	}
+}
+
+// isTrue is true for a boolean value that's true.
+func isTrue(n *yaml.Node) bool {
+	if n == nil || n.Kind != yaml.ScalarNode {
+		return false
+	}
+	b, err := strconv.ParseBool(n.Value)
+	return err == nil && b
+}
+
+// intValue reads a number, /unset/ when it's missing or isn't a number.
+func intValue(n *yaml.Node, unset int) int {
+	if n == nil || n.Kind != yaml.ScalarNode {
+		return unset
+	}
+	i, err := strconv.Atoi(n.Value)
+	if err != nil {
+		return unset
+	}
+	return i
+}
+
+// stringMap reads a map of scalars, e.g.: resources.requests.
+func stringMap(n *yaml.Node) map[string]string {
+	if n == nil || n.Kind != yaml.MappingNode || len(n.Content) == 0 {
+		return nil
+	}
+	m := make(map[string]string)
+	for i := 0; i+1 < len(n.Content); i += 2 {
+		if v := n.Content[i+1]; v.Kind == yaml.ScalarNode && isSet(v) {
+			m[n.Content[i].Value] = v.Value
+		}
+	}
+	return m
+}
+
+// AddValues reads a chart's values file as the Workload the chart deploys, named /chart/.
+// A values file that configures none of the mapped settings is most likely for someone else's chart, so it's skipped.
+func (m *Manifests) AddValues(file, chart string, r io.Reader, hm HelmMapping) error {
+	var doc yaml.Node
+	if err := yaml.NewDecoder(r).Decode(&doc); err != nil && err != io.EOF {
+		return fmt.Errorf("%s: %v", file, err)
+	}
+	if len(doc.Content) == 0 {
+		return nil
+	}
+	values := doc.Content[0]
+
+	configured := false
+	for _, p := range []string{hm.Replicas, hm.Autoscaling, hm.Resources, hm.LivenessProbe, hm.ReadinessProbe, hm.DisruptionBudget} {
+		if lookupValue(values, p) != nil {
+			configured = true
+		}
+	}
+	if !configured {
+		return nil
+	}
+	if !slices.Contains(m.Files, file) {
+		// e.g.: kube/values.yaml, already read as a manifest with no Kubernetes objects in it
+		m.Files = append(m.Files, file)
+	}
+
+	resources := lookupValue(values, hm.Resources)
+	w := Workload{
+		Kind:     "Chart",
+		Name:     chart,
+		Source:   file,
+		Replicas: intValue(lookupValue(values, hm.Replicas), 1), // helm create defaults it to 1
+		Containers: []Container{{
+			Name:      chart,
+			Liveness:  isSet(lookupValue(values, hm.LivenessProbe)),
+			Readiness: isSet(lookupValue(values, hm.ReadinessProbe)),
+			Requests:  stringMap(lookupValue(resources, "requests")),
+			Limits:    stringMap(lookupValue(resources, "limits")),
+		}},
+		Settings: hm.settings(),
+	}
+	if isTrue(lookupValue(values, hm.Autoscaling)) {
+		w.Autoscaler = &Autoscaler{
+			Name:        hm.Autoscaling,
+			MinReplicas: intValue(lookupValue(values, hm.MinReplicas), 1),
+			MaxReplicas: intValue(lookupValue(values, hm.MaxReplicas), 0),
+		}
+	}
+
+	// Either enabled: true, a bare true, or a minAvailable or maxUnavailable that isn't switched off
+	budget := lookupValue(values, hm.DisruptionBudget)
+	enabled := lookupValue(budget, "enabled")
+	switch {
+	case isTrue(budget), isTrue(enabled):
+		w.Budget = hm.DisruptionBudget
+	case enabled == nil && (isSet(lookupValue(budget, "minAvailable")) || isSet(lookupValue(budget, "maxUnavailable"))):
+		w.Budget = hm.DisruptionBudget
+	}
+
+	m.Workloads = append(m.Workloads, w)
+	return nil
+}
+
+// fetchValues reads the Helm values files at /paths/ in /repo/ into /m/.
+// The chart's name comes from the Chart.yaml beside the values, or else the repository's.
+func fetchValues(gh *GitHubClient, repo string, paths []string, hm HelmMapping, m *Manifests) error {
+	for _, p := range paths {
+		body, ok, err := fetchOptional(gh, repo, p)
+		if err != nil {
+			return err
+		}
+		if !ok {
+			continue
+		}
+
+		chart := path.Base(repo)
+		if meta, ok, _ := fetchOptional(gh, repo, path.Join(path.Dir(p), "Chart.yaml")); ok {
+			var c struct {
+				Name string `yaml:"name"`
+			}
+			if yaml.Unmarshal([]byte(meta), &c) == nil && c.Name != "" {
+				chart = c.Name
+			}
+		}
+
+		if err := m.AddValues(p, chart, strings.NewReader(body), hm); err != nil {
+			slog.Warn("Unreadable Values", slog.String("Repo", repo), slog.Any("Error", err))
+		}
+	}
+	return nil
+}
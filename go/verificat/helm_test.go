@@ -0,0 +1,140 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+// A chart's values become the Workload it deploys
+func TestAddValues(t *testing.T) {
+	valuesTests := []struct {
+		Name   string
+		Values string
+		Want   []Workload
+	}{
+		{
+			"helm create defaults",
+			"replicaCount: 1\nresources: {}\nautoscaling:\n  enabled: false\n  minReplicas: 1\n",
+			[]Workload{{Kind: "Chart", Name: "app", Source: "values.yaml", Replicas: 1, Containers: []Container{{Name: "app"}}}},
+		},
+		{
+			"autoscaled with a budget",
+			`replicaCount: 2
+autoscaling: {enabled: true, minReplicas: 3, maxReplicas: 9}
+podDisruptionBudget: {minAvailable: 1}
+livenessProbe: {httpGet: {path: /healthz}}
+readinessProbe: null
+resources:
+  requests: {cpu: 1, memory: ~}
+  limits: {memory: 1Gi}
+`,
+			[]Workload{{
+				Kind: "Chart", Name: "app", Source: "values.yaml", Replicas: 2,
+				Containers: []Container{{Name: "app", Liveness: true, Requests: map[string]string{"cpu": "1"}, Limits: map[string]string{"memory": "1Gi"}}},
+				Autoscaler: &Autoscaler{Name: "autoscaling.enabled", MinReplicas: 3, MaxReplicas: 9},
+				Budget:     "podDisruptionBudget",
+			}},
+		},
+		{
+			"a budget switched off",
+			"podDisruptionBudget: {enabled: false, minAvailable: 1}\n",
+			[]Workload{{Kind: "Chart", Name: "app", Source: "values.yaml", Replicas: 1, Containers: []Container{{Name: "app"}}}},
+		},
+		{
+			"a budget that's only a switch",
+			"podDisruptionBudget: true\n",
+			[]Workload{{Kind: "Chart", Name: "app", Source: "values.yaml", Replicas: 1, Containers: []Container{{Name: "app"}}, Budget: "podDisruptionBudget"}},
+		},
+		{"values for someone else's chart", "dnsPolicy: None\nextraEnv: []\n", nil},
+		{"empty", "", nil},
+	}
+
+	for _, tt := range valuesTests {
+		t.Run(tt.Name, func(t *testing.T) {
+			m := new(Manifests)
+			assertNoError(t, m.AddValues("values.yaml", "app", strings.NewReader(tt.Values), defaultHelmMapping))
+
+			for i := range m.Workloads {
+				m.Workloads[i].Settings = nil // Compared below
+			}
+			if diff := cmp.Diff(m.Workloads, tt.Want); diff != "" {
+				t.Error(diff)
+			}
+		})
+	}
+
+	t.Run("settings point at the mapped values", func(t *testing.T) {
+		mapping := defaultHelmMapping
+		mapping.Replicas = "app.replicas"
+		mapping.Resources = "app.resources"
+
+		m := new(Manifests)
+		assertNoError(t, m.AddValues("values.yaml", "app", strings.NewReader("app:\n  replicas: 4\n"), mapping))
+		w := m.Workloads[0]
+		assertIDEquals(t, w.Replicas, 4)
+		assertString(t, w.Setting("replicas"), "app.replicas")
+		assertString(t, w.Setting("resources"), "app.resources")
+		assertString(t, Workload{}.Setting("resources"), "resources")
+	})
+
+	t.Run("unparsable values", func(t *testing.T) {
+		m := new(Manifests)
+		if err := m.AddValues("values.yaml", "app", strings.NewReader("replicaCount: [1\n"), defaultHelmMapping); err == nil {
+			t.Error("wanted an error")
+		}
+	})
+}
+
+// HELM_MAPPING_FILE replaces the paths it sets
+func TestNewHelmMapping(t *testing.T) {
+	assertString(t, NewHelmMapping().Replicas, "replicaCount")
+
+	file := filepath.Join(t.TempDir(), "mapping.yaml")
+	assertNoError(t, os.WriteFile(file, []byte("replicas: web.replicas\ndisruptionBudget: web.pdb\n"), 0o644))
+	t.Setenv("HELM_MAPPING_FILE", file)
+
+	want := defaultHelmMapping
+	want.Replicas = "web.replicas"
+	want.DisruptionBudget = "web.pdb"
+	if diff := cmp.Diff(NewHelmMapping(), want); diff != "" {
+		t.Error(diff)
+	}
+
+	t.Setenv("HELM_MAPPING_FILE", filepath.Join(t.TempDir(), "missing.yaml"))
+	if diff := cmp.Diff(NewHelmMapping(), defaultHelmMapping); diff != "" {
+		t.Error(diff)
+	}
+
+	// A typo is an error, not a setting quietly left at its default
+	assertNoError(t, os.WriteFile(file, []byte("replicas: web.replicas\nreplicaCont: web.count\n"), 0o644))
+	t.Setenv("HELM_MAPPING_FILE", file)
+	if diff := cmp.Diff(NewHelmMapping(), defaultHelmMapping); diff != "" {
+		t.Error(diff)
+	}
+}
+
+// Values are read from the repository, named by the Chart.yaml beside them
+func TestFetchValues(t *testing.T) {
+	gh, _ := newFakeGitHub(t, fakeGitHubFixtures)
+
+	m := new(Manifests)
+	assertNoError(t, fetchValues(gh, "GhostGroup/ad-server", helmPaths, defaultHelmMapping, m))
+	if len(m.Workloads) != 1 {
+		t.Fatalf("got %d workloads want 1", len(m.Workloads))
+	}
+	w := m.Workloads[0]
+	assertString(t, w.String(), "Chart/ad-server")
+	assertString(t, w.Source, "chart/values.yaml")
+	assertIDEquals(t, w.MinReplicas(), 3)
+	assertString(t, w.Budget, "podDisruptionBudget")
+
+	t.Run("named after the repository without a Chart.yaml", func(t *testing.T) {
+		m := new(Manifests)
+		assertNoError(t, fetchValues(gh, "GhostGroup/admin", helmPaths, defaultHelmMapping, m))
+		assertString(t, m.Workloads[0].String(), "Chart/admin")
+	})
+}
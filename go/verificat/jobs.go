@@ -0,0 +1,173 @@
+package main
+
+import (
+	"errors"
+	"log/slog"
+	"sync"
+	"time"
+)
+
+const (
+	jobWorkers    = 4    // Verifications running at once
+	jobQueueDepth = 256  // Verifications waiting for a worker before we turn requests away
+	jobsKept      = 1024 // Finished jobs kept around for status lookups
+)
+
+var QueueFull = errors.New("verification queue is full")
+
+// JobStatus is where a verification job is in its life.
+type JobStatus string
+
+const (
+	JobQueued   JobStatus = "queued"
+	JobRunning  JobStatus = "running"
+	JobFinished JobStatus = "finished"
+	JobFailed   JobStatus = "failed"
+)
+
+// Job is one queued verification of a service.
+// Times are Unix Epoch in seconds, like SvcConfig.Datetime.
+type Job struct {
+	ID       int           // Job ID, unique for the life of the server
+	Service  string        // The service being verified
+	Status   JobStatus     // queued, running, finished or failed
+	Queued   int64         // When the job was accepted
+	Started  int64         `json:",omitempty"` // When a worker picked it up
+	Finished int64         `json:",omitempty"` // When it finished or failed
+	Error    string        `json:",omitempty"` // Why it failed
+	Result   *VerifyResult `json:",omitempty"` // The full result, once finished
+
+	done chan struct{} // closed when the job is finished or failed
+}
+
+// VerifyFunc runs a full verification of a service and stores the result.
+type VerifyFunc func(service string) (*VerifyResult, error)
+
+// JobQueue hands verifications to a bounded pool of workers,
+// so a request can be answered right away instead of waiting on Backstage and GitHub.
+type JobQueue struct {
+	mu       sync.Mutex
+	queue    chan *Job
+	jobs     map[int]*Job
+	finished []int // IDs of finished jobs, oldest first, for trimming
+	lastID   int
+	verify   VerifyFunc
+}
+
+// NewJobQueue Constructor
+// Starts /workers/ goroutines pulling from a queue that holds up to /depth/ jobs.
+func NewJobQueue(workers, depth int, verify VerifyFunc) *JobQueue {
+	q := &JobQueue{
+		queue:  make(chan *Job, depth),
+		jobs:   make(map[int]*Job),
+		verify: verify,
+	}
+
+	for i := 0; i < workers; i++ {
+		go q.work()
+	}
+
+	return q
+}
+
+// Submit queues a verification of /service/ and returns the new Job.
+// If every worker is busy and the queue is full, it returns QueueFull.
+func (q *JobQueue) Submit(service string) (Job, error) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	job := &Job{
+		ID:      q.lastID + 1,
+		Service: service,
+		Status:  JobQueued,
+		Queued:  time.Now().Unix(),
+		done:    make(chan struct{}),
+	}
+
+	select {
+	case q.queue <- job:
+	default:
+		slog.Warn("Job Rejected", slog.String("Service", service), slog.Any("Error", QueueFull))
+		return Job{}, QueueFull
+	}
+
+	q.lastID = job.ID
+	q.jobs[job.ID] = job
+	slog.Info("Job Queued", slog.Int("JobID", job.ID), slog.String("Service", service))
+	return *job, nil
+}
+
+// Get returns a snapshot of a Job by its ID.
+func (q *JobQueue) Get(id int) (Job, bool) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	job, ok := q.jobs[id]
+	if !ok {
+		return Job{}, false
+	}
+	return *job, true
+}
+
+// Wait blocks until the Job is finished or failed, or the timeout passes.
+// It reports whether the Job is done.
+func (q *JobQueue) Wait(id int, timeout time.Duration) bool {
+	q.mu.Lock()
+	job, ok := q.jobs[id]
+	q.mu.Unlock()
+	if !ok {
+		return false
+	}
+
+	select {
+	case <-job.done:
+		return true
+	case <-time.After(timeout):
+		return false
+	}
+}
+
+// work runs queued jobs one at a time until the queue is closed.
+func (q *JobQueue) work() {
+	for job := range q.queue {
+		q.update(job, func(j *Job) {
+			j.Status = JobRunning
+			j.Started = time.Now().Unix()
+		})
+
+		result, err := q.verify(job.Service)
+
+		q.update(job, func(j *Job) {
+			j.Finished = time.Now().Unix()
+			j.Result = result
+			j.Status = JobFinished
+			if err != nil {
+				j.Status = JobFailed
+				j.Error = err.Error()
+			}
+		})
+		close(job.done)
+
+		slog.Info("Job Done", slog.Int("JobID", job.ID), slog.String("Service", job.Service), slog.Any("Status", job.Status))
+		q.trim(job.ID)
+	}
+}
+
+// update changes a Job while holding the lock, so snapshots are never torn.
+func (q *JobQueue) update(job *Job, change func(j *Job)) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	change(job)
+}
+
+// trim forgets the oldest finished jobs once more than jobsKept are held.
+func (q *JobQueue) trim(id int) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	q.finished = append(q.finished, id)
+	for len(q.finished) > jobsKept {
+		delete(q.jobs, q.finished[0])
+		q.finished = q.finished[1:]
+	}
+}
@@ -0,0 +1,126 @@
+package main
+
+import (
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestJobQueue(t *testing.T) {
+	t.Run("jobs run in the background and keep their result", func(t *testing.T) {
+		queue := NewJobQueue(2, 10, func(service string) (*VerifyResult, error) {
+			return &VerifyResult{Service: service, Score: 100, Status: Ready}, nil
+		})
+
+		job, err := queue.Submit("admin")
+		assertNoError(t, err)
+		assertIDEquals(t, job.ID, 1)
+
+		if !queue.Wait(job.ID, time.Second) {
+			t.Fatal("job did not finish")
+		}
+
+		got, ok := queue.Get(job.ID)
+		if !ok {
+			t.Fatal("finished job was not found")
+		}
+		if got.Status != JobFinished || got.Result.Service != "admin" || got.Finished == 0 {
+			t.Errorf("got job %+v want finished admin job", got)
+		}
+	})
+
+	t.Run("errors fail the job", func(t *testing.T) {
+		queue := NewJobQueue(1, 10, func(service string) (*VerifyResult, error) {
+			return nil, fmt.Errorf("environment variable BACKSTAGE not set")
+		})
+
+		job, _ := queue.Submit("admin")
+		queue.Wait(job.ID, time.Second)
+
+		got, _ := queue.Get(job.ID)
+		if got.Status != JobFailed || got.Error != "environment variable BACKSTAGE not set" {
+			t.Errorf("got job %+v want failed job", got)
+		}
+	})
+
+	t.Run("job IDs are unique under load", func(t *testing.T) {
+		queue := NewJobQueue(4, 100, func(service string) (*VerifyResult, error) {
+			return &VerifyResult{Service: service}, nil
+		})
+
+		var mu sync.Mutex
+		seen := map[int]bool{}
+		var wg sync.WaitGroup
+		for i := 0; i < 50; i++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				job, err := queue.Submit("admin")
+				assertNoError(t, err)
+				queue.Wait(job.ID, time.Second)
+
+				mu.Lock()
+				defer mu.Unlock()
+				seen[job.ID] = true
+			}()
+		}
+		wg.Wait()
+
+		assertIDEquals(t, len(seen), 50)
+	})
+
+	t.Run("a full queue is rejected", func(t *testing.T) {
+		release := make(chan struct{})
+		queue := NewJobQueue(1, 1, func(service string) (*VerifyResult, error) {
+			<-release
+			return &VerifyResult{Service: service}, nil
+		})
+		defer close(release)
+
+		running, _ := queue.Submit("admin")
+		for i := 0; i < 1000; i++ {
+			if job, _ := queue.Get(running.ID); job.Status == JobRunning {
+				break
+			}
+			time.Sleep(time.Millisecond)
+		}
+		_, err := queue.Submit("admin")
+		assertNoError(t, err)
+
+		_, err = queue.Submit("admin")
+		if err != QueueFull {
+			t.Errorf("got error %v want %v", err, QueueFull)
+		}
+	})
+
+	t.Run("unknown jobs are not found", func(t *testing.T) {
+		queue := NewJobQueue(1, 1, nil)
+
+		if _, ok := queue.Get(42); ok {
+			t.Error("found a job that was never submitted")
+		}
+		if queue.Wait(42, time.Millisecond) {
+			t.Error("waited on a job that was never submitted")
+		}
+	})
+
+	t.Run("only the newest finished jobs are kept", func(t *testing.T) {
+		queue := NewJobQueue(1, jobsKept+10, func(service string) (*VerifyResult, error) {
+			return &VerifyResult{Service: service}, nil
+		})
+
+		var last Job
+		for i := 0; i < jobsKept+5; i++ {
+			last, _ = queue.Submit("admin")
+		}
+		queue.Wait(last.ID, 10*time.Second)
+
+		if _, ok := queue.Get(1); ok {
+			t.Error("the oldest job should have been forgotten")
+		}
+		if _, ok := queue.Get(last.ID); !ok {
+			t.Error("the newest job should be kept")
+		}
+	})
+}
@@ -1,6 +1,9 @@
 package main
 
 import (
+	"context"
+	"database/sql"
+	"fmt"
 	"log"
 	"log/slog"
 	"net/http"
@@ -8,10 +11,11 @@ import (
 )
 
 const (
-	app        = "verificat"
-	dbFileName = "almanac.db.json"
-	runPort    = "4330"          // TODO: this should be configurable
-	llvl       = slog.LevelDebug // TODO: this should be configurable
+	app         = "verificat"
+	dbFileName  = "almanac.db.json"
+	sqlFileName = "almanac.db.sqlite"
+	runPort     = "4330"          // TODO: this should be configurable
+	llvl        = slog.LevelDebug // TODO: this should be configurable
 )
 
 // Main connects a local JSON database to a running API service.
@@ -24,21 +28,102 @@ func main() {
 		slog.String("port", runPort),
 	)
 
-	// Open JSON Database file
+	// Authenticate to GitHub as an App when one is configured, otherwise with GH_TOKEN
+	// A configured App that can't get a token stops here, rather than every request going unauthenticated
+	appTokens, err := AppTokenSourceFromEnv(defaultGitHub.BaseURL)
+	switch {
+	case err != nil:
+		log.Fatalf("problem configuring GitHub App authentication, %v", err)
+	case appTokens != nil:
+		if _, err := appTokens.Token(); err != nil {
+			log.Fatalf("problem getting a GitHub App installation token, %v", err)
+		}
+		defaultGitHub.Token = appTokens.Token
+		slog.Info("GitHub Auth", slog.String("Method", "app"), slog.String("AppID", appTokens.AppID))
+	default:
+		slog.Info("GitHub Auth", slog.String("Method", "GH_TOKEN"))
+	}
+
+	// STORE selects the database, the JSON file is the default
+	var store ServiceStore
+	switch fillEnvVar("STORE") {
+	case "sqlite":
+		store = openSQLStore()
+	default:
+		store = openFSStore()
+	}
+
+	// A NewVerificationServ is configured with the database on local disk
+	server := NewVerificationServ(store)
+
+	// The Scheduler keeps the whole catalog verified without anyone asking
+	if config := NewScheduleConfig(); config.Interval > 0 {
+		server.scheduler = NewScheduler(config, discoverSystems, server.jobs)
+		go server.scheduler.Run(context.Background())
+		slog.Info("Scheduler Started",
+			slog.String("Interval", config.Interval.String()),
+			slog.String("Jitter", config.Jitter.String()),
+			slog.Int("Concurrency", config.Concurrency),
+		)
+	}
+
+	if err := http.ListenAndServe(":"+runPort, server); err != nil {
+		slog.Error("Servercrash")
+	}
+}
+
+// openFSStore connects File System Storage operations to the JSON Database.
+func openFSStore() *FSStore {
 	db, err := os.OpenFile(dbFileName, os.O_RDWR|os.O_CREATE, 0666)
 	if err != nil {
 		log.Fatalf("problem opening %s %v", dbFileName, err)
 	}
 
-	// Connect File System Storage operations to JSON Database
 	store, err := NewFSStore(db)
 	if err != nil {
 		log.Fatalf("problem creating file system service store, %v ", err)
 	}
+	slog.Info("Database Opened", slog.String("Store", "json"), slog.String("File", dbFileName))
+	return store
+}
 
-	// A NewVerificationServ is configured with the database on local disk
-	server := NewVerificationServ(store)
-	if err := http.ListenAndServe(":"+runPort, server); err != nil {
-		slog.Error("Servercrash")
+// openSQLStore connects SQL Storage operations to the embedded SQLite Database.
+// On the first start, an existing JSON Database is imported so no history is lost.
+func openSQLStore() *SQLStore {
+	db, err := sql.Open("sqlite", sqlFileName+"?_pragma=busy_timeout(5000)&_pragma=journal_mode(WAL)")
+	if err != nil {
+		log.Fatalf("problem opening %s %v", sqlFileName, err)
+	}
+
+	store, err := NewSQLStore(db)
+	if err != nil {
+		log.Fatalf("problem creating sql service store, %v ", err)
+	}
+	slog.Info("Database Opened", slog.String("Store", "sqlite"), slog.String("File", sqlFileName))
+
+	if len(store.GetAlmanac()) > 0 {
+		return store
+	}
+	if jsonDB, err := os.Open(dbFileName); err == nil {
+		defer jsonDB.Close()
+		almanac, history, err := NewDatabase(jsonDB)
+		if err != nil {
+			log.Fatalf("problem reading %s for import, %v", dbFileName, err)
+		}
+		if err := store.Import(almanac, history); err != nil {
+			log.Fatalf("problem importing %s, %v", dbFileName, err)
+		}
+		slog.Info("Database Imported", slog.String("File", dbFileName), slog.Int("Services", len(almanac)), slog.Int("Runs", len(history)))
+	}
+	return store
+}
+
+// discoverSystems lists the Systems in the Backstage catalog set by BACKSTAGE.
+func discoverSystems() ([]string, error) {
+	envVar := "BACKSTAGE"
+	url := fillEnvVar(envVar)
+	if url == "ENOENT" {
+		return nil, fmt.Errorf("environment variable %s not set", envVar)
 	}
+	return ListSystems(url)
 }
@@ -0,0 +1,312 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"path"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// kubePaths are where Kubernetes manifests are looked for in the Service's repository,
+// each a YAML file or a directory of them. KUBE_PATHS replaces them.
+var kubePaths = []string{"kube", "k8s", "deploy", "manifests"}
+
+// Workload is a Deployment or StatefulSet, or the Chart rendering one, as far as its readiness goes.
+type Workload struct {
+	Kind       string            // Deployment, StatefulSet or Chart
+	Name       string            // e.g.: verificat
+	Source     string            // The file it was read from
+	Line       int               // Where it starts in that file
+	Replicas   int               // spec.replicas, which Kubernetes defaults to 1
+	Labels     map[string]string // The pod template's labels, what a PodDisruptionBudget selects
+	Containers []Container
+	Autoscaler *Autoscaler       // The HorizontalPodAutoscaler scaling it, if there is one
+	Budget     string            // The PodDisruptionBudget covering it, if there is one
+	Settings   map[string]string // Where a Chart's values configure each field, e.g.: replicas is replicaCount
+}
+
+// String names the workload the way kubectl does, e.g.: Deployment/verificat.
+func (w Workload) String() string {
+	return w.Kind + "/" + w.Name
+}
+
+// Setting is the name to give /field/ in a Finding:
+// the value configuring it for a Chart, the field itself for a manifest.
+func (w Workload) Setting(field string) string {
+	if s := w.Settings[field]; s != "" {
+		return s
+	}
+	return field
+}
+
+// MinReplicas is the fewest pods the workload runs,
+// its autoscaler's floor when it has one.
+func (w Workload) MinReplicas() int {
+	if w.Autoscaler != nil {
+		return w.Autoscaler.MinReplicas
+	}
+	return w.Replicas
+}
+
+// Container is one container of a Workload's pods.
+type Container struct {
+	Name      string
+	Line      int
+	Liveness  bool              // Has a livenessProbe
+	Readiness bool              // Has a readinessProbe
+	Requests  map[string]string // resources.requests, e.g.: cpu: 125m
+	Limits    map[string]string // resources.limits
+}
+
+// Autoscaler is the HorizontalPodAutoscaler of a Workload.
+type Autoscaler struct {
+	Name        string
+	MinReplicas int // spec.minReplicas, which Kubernetes defaults to 1
+	MaxReplicas int
+}
+
+// Manifests are the workloads found in a repository,
+// with their PodDisruptionBudgets and HorizontalPodAutoscalers tied to them.
+type Manifests struct {
+	Files     []string // Every file read, in order
+	Workloads []Workload
+
+	budgets     []kubeObject
+	autoscalers []kubeObject
+}
+
+// kubeObject is the part of a Kubernetes object Verificat reads.
+// The spec holds the fields of every kind it reads, each kind only fills in its own.
+type kubeObject struct {
+	Kind     string `yaml:"kind"`
+	Metadata struct {
+		Name string `yaml:"name"`
+	} `yaml:"metadata"`
+	Spec struct {
+		// Deployment and StatefulSet
+		Replicas *int `yaml:"replicas"`
+		Template struct {
+			Metadata struct {
+				Labels map[string]string `yaml:"labels"`
+			} `yaml:"metadata"`
+			Spec struct {
+				Containers []kubeContainer `yaml:"containers"`
+			} `yaml:"spec"`
+		} `yaml:"template"`
+
+		// PodDisruptionBudget
+		Selector struct {
+			MatchLabels map[string]string `yaml:"matchLabels"`
+		} `yaml:"selector"`
+
+		// HorizontalPodAutoscaler
+		ScaleTargetRef struct {
+			Kind string `yaml:"kind"`
+			Name string `yaml:"name"`
+		} `yaml:"scaleTargetRef"`
+		MinReplicas *int `yaml:"minReplicas"`
+		MaxReplicas int  `yaml:"maxReplicas"`
+	} `yaml:"spec"`
+
+	line int
+}
+
+// kubeContainer is the part of a container Verificat reads.
+type kubeContainer struct {
+	Name           string    `yaml:"name"`
+	LivenessProbe  yaml.Node `yaml:"livenessProbe"`
+	ReadinessProbe yaml.Node `yaml:"readinessProbe"`
+	Resources      struct {
+		Requests map[string]string `yaml:"requests"`
+		Limits   map[string]string `yaml:"limits"`
+	} `yaml:"resources"`
+
+	line int
+}
+
+// UnmarshalYAML keeps the line the container starts on.
+func (c *kubeContainer) UnmarshalYAML(n *yaml.Node) error {
+	type plain kubeContainer
+	c.line = n.Line
+	return n.Decode((*plain)(c))
+}
+
+// Add reads every document in a manifest file.
+// Kinds other than Deployments, StatefulSets, PodDisruptionBudgets and HorizontalPodAutoscalers are skipped,
+// and so are documents that aren't Kubernetes objects at all.
+func (m *Manifests) Add(file string, r io.Reader) error {
+	m.Files = append(m.Files, file)
+
+	d := yaml.NewDecoder(r)
+	for {
+		var doc yaml.Node
+		err := d.Decode(&doc)
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return fmt.Errorf("%s: %v", file, err)
+		}
+		if len(doc.Content) == 0 || doc.Content[0].Kind != yaml.MappingNode {
+			continue
+		}
+
+		var obj kubeObject
+		if err := doc.Content[0].Decode(&obj); err != nil {
+			return fmt.Errorf("%s:%d: %v", file, doc.Content[0].Line, err)
+		}
+		obj.line = doc.Content[0].Line
+
+		switch obj.Kind {
+		case "Deployment", "StatefulSet":
+			m.Workloads = append(m.Workloads, newWorkload(file, obj))
+		case "PodDisruptionBudget":
+			m.budgets = append(m.budgets, obj)
+		case "HorizontalPodAutoscaler":
+			m.autoscalers = append(m.autoscalers, obj)
+		}
+	}
+}
+
+// newWorkload reads a Deployment or StatefulSet.
+func newWorkload(file string, obj kubeObject) Workload {
+	w := Workload{
+		Kind:     obj.Kind,
+		Name:     obj.Metadata.Name,
+		Source:   file,
+		Line:     obj.line,
+		Replicas: 1,
+		Labels:   obj.Spec.Template.Metadata.Labels,
+	}
+	if obj.Spec.Replicas != nil {
+		w.Replicas = *obj.Spec.Replicas
+	}
+	for _, c := range obj.Spec.Template.Spec.Containers {
+		w.Containers = append(w.Containers, Container{
+			Name:      c.Name,
+			Line:      c.line,
+			Liveness:  !c.LivenessProbe.IsZero(),
+			Readiness: !c.ReadinessProbe.IsZero(),
+			Requests:  c.Resources.Requests,
+			Limits:    c.Resources.Limits,
+		})
+	}
+	return w
+}
+
+// Link ties each PodDisruptionBudget and HorizontalPodAutoscaler to the workloads it covers,
+// once every file has been added.
+// A budget covers the workloads whose pod labels include all of its selector's,
+// an autoscaler the workload its scaleTargetRef names.
+func (m *Manifests) Link() {
+	for i := range m.Workloads {
+		w := &m.Workloads[i]
+		for _, pdb := range m.budgets {
+			selector := pdb.Spec.Selector.MatchLabels
+			if len(selector) > 0 && labelsMatch(selector, w.Labels) {
+				w.Budget = pdb.Metadata.Name
+				break
+			}
+		}
+		for _, hpa := range m.autoscalers {
+			if hpa.Spec.ScaleTargetRef.Kind == w.Kind && hpa.Spec.ScaleTargetRef.Name == w.Name {
+				w.Autoscaler = &Autoscaler{Name: hpa.Metadata.Name, MinReplicas: 1, MaxReplicas: hpa.Spec.MaxReplicas}
+				if hpa.Spec.MinReplicas != nil {
+					w.Autoscaler.MinReplicas = *hpa.Spec.MinReplicas
+				}
+				break
+			}
+		}
+	}
+}
+
+// labelsMatch is true when every label in /selector/ is in /labels/.
+func labelsMatch(selector, labels map[string]string) bool {
+	for k, v := range selector {
+		if labels[k] != v {
+			return false
+		}
+	}
+	return true
+}
+
+// manifestsRead is the repository's manifests, read once per run.
+type manifestsRead struct {
+	manifests *Manifests
+	err       error
+	charged   string // The Check that took the point for err, so the others don't
+}
+
+// readManifests fetches and parses the Service's Kubernetes manifests and Helm values the first time they're asked for.
+func (s *SvcTestDB) readManifests() (*Manifests, error) {
+	if s.manifests == nil {
+		m, err := fetchManifests(s.github(), s.Repo, envList("KUBE_PATHS", kubePaths))
+		if err == nil {
+			err = fetchValues(s.github(), s.Repo, envList("HELM_VALUES_PATHS", helmPaths), NewHelmMapping(), m)
+		}
+		s.manifests = &manifestsRead{manifests: m, err: err}
+	}
+	return s.manifests.manifests, s.manifests.err
+}
+
+// fetchManifests reads every YAML file at /paths/ in /repo/.
+// A path that's a directory has each of its .yaml and .yml files read, but not its subdirectories.
+// A file stops being read where it doesn't parse, it's most likely a template of some other tool.
+func fetchManifests(gh *GitHubClient, repo string, paths []string) (*Manifests, error) {
+	if repo == "" {
+		return nil, errRepoUnresolved
+	}
+
+	m := new(Manifests)
+	add := func(p, body string) {
+		if err := m.Add(p, strings.NewReader(body)); err != nil {
+			slog.Warn("Unreadable Manifest", slog.String("Repo", repo), slog.Any("Error", err))
+		}
+	}
+
+	for _, p := range paths {
+		body, ok, err := fetchOptional(gh, repo, p)
+		if err != nil {
+			return nil, err
+		}
+		if !ok {
+			continue
+		}
+
+		var listing []ghContent
+		if json.Unmarshal([]byte(body), &listing) != nil {
+			add(p, body)
+			continue
+		}
+		for _, entry := range listing {
+			if entry.Type != "file" || (path.Ext(entry.Name) != ".yaml" && path.Ext(entry.Name) != ".yml") {
+				continue
+			}
+			body, ok, err := fetchOptional(gh, repo, entry.Path)
+			if err != nil {
+				return nil, err
+			}
+			if ok {
+				add(entry.Path, body)
+			}
+		}
+	}
+
+	m.Link()
+	slog.Info("Manifests Read", slog.String("Repo", repo), slog.Any("Files", m.Files), slog.Int("Workloads", len(m.Workloads)))
+	return m, nil
+}
+
+// fetchOptional fetches a file or directory listing that may well not exist, which isn't an error.
+func fetchOptional(gh *GitHubClient, repo, p string) (string, bool, error) {
+	body, err := gh.Get(urlCat(gh.BaseURL, ghPreURI, repo, ghContentsPATH, p))
+	if errors.Is(err, GitHubNotFound) {
+		return "", false, nil
+	}
+	return body, err == nil, err
+}
@@ -0,0 +1,218 @@
+package main
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+// Every document of a file is read, and only the kinds Verificat cares about are kept
+func TestManifestsAdd(t *testing.T) {
+	manifest := `# A comment before the first document
+apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: settings
+---
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: web
+spec:
+  replicas: 2
+  template:
+    metadata:
+      labels:
+        app: web
+        tier: front
+    spec:
+      containers:
+      - name: web
+        livenessProbe:
+          httpGet:
+            path: /healthz
+        resources:
+          requests:
+            cpu: 100m
+      - name: proxy
+        readinessProbe:
+          tcpSocket:
+            port: 8080
+        resources:
+          limits:
+            memory: 64Mi
+---
+---
+just a string
+---
+apiVersion: apps/v1
+kind: StatefulSet
+metadata:
+  name: db
+spec:
+  template:
+    spec:
+      containers:
+      - name: db
+---
+apiVersion: policy/v1
+kind: PodDisruptionBudget
+metadata:
+  name: web
+spec:
+  selector:
+    matchLabels:
+      app: web
+---
+apiVersion: autoscaling/v2
+kind: HorizontalPodAutoscaler
+metadata:
+  name: db
+spec:
+  scaleTargetRef:
+    kind: StatefulSet
+    name: db
+  minReplicas: 3
+  maxReplicas: 5
+`
+
+	m := new(Manifests)
+	assertNoError(t, m.Add("kube/app.yaml", strings.NewReader(manifest)))
+	m.Link()
+
+	want := []Workload{
+		{
+			Kind: "Deployment", Name: "web", Source: "kube/app.yaml", Line: 7, Replicas: 2,
+			Labels: map[string]string{"app": "web", "tier": "front"},
+			Containers: []Container{
+				{Name: "web", Line: 20, Liveness: true, Requests: map[string]string{"cpu": "100m"}},
+				{Name: "proxy", Line: 27, Readiness: true, Limits: map[string]string{"memory": "64Mi"}},
+			},
+			Budget: "web",
+		},
+		{
+			Kind: "StatefulSet", Name: "db", Source: "kube/app.yaml", Line: 38, Replicas: 1,
+			Containers: []Container{{Name: "db", Line: 46}},
+			Autoscaler: &Autoscaler{Name: "db", MinReplicas: 3, MaxReplicas: 5},
+		},
+	}
+	if diff := cmp.Diff(m.Workloads, want); diff != "" {
+		t.Error(diff)
+	}
+	if diff := cmp.Diff(m.Files, []string{"kube/app.yaml"}); diff != "" {
+		t.Error(diff)
+	}
+
+	assertString(t, m.Workloads[0].String(), "Deployment/web")
+	assertIDEquals(t, m.Workloads[0].MinReplicas(), 2)
+	assertIDEquals(t, m.Workloads[1].MinReplicas(), 3)
+
+	t.Run("a template is skipped from where it stops parsing", func(t *testing.T) {
+		m := new(Manifests)
+		err := m.Add("chart/deployment.yaml", strings.NewReader("kind: Deployment\nmetadata:\n  name: {{ .Release.Name }\n"))
+		if err == nil {
+			t.Error("wanted an error")
+		}
+		if len(m.Workloads) != 0 {
+			t.Errorf("got %d workloads want none", len(m.Workloads))
+		}
+	})
+}
+
+// A PodDisruptionBudget covers the workloads whose pods have all of its labels
+func TestManifestsLink(t *testing.T) {
+	m := &Manifests{
+		Workloads: []Workload{
+			{Kind: "Deployment", Name: "api", Labels: map[string]string{"app": "api"}},
+			{Kind: "Deployment", Name: "worker", Labels: map[string]string{"app": "worker", "queue": "default"}},
+			{Kind: "StatefulSet", Name: "api"},
+		},
+	}
+	for _, budget := range []string{
+		"kind: PodDisruptionBudget\nmetadata: {name: worker}\nspec: {selector: {matchLabels: {app: worker, queue: default}}}\n",
+		"kind: PodDisruptionBudget\nmetadata: {name: everything}\nspec: {selector: {}}\n",
+		"kind: PodDisruptionBudget\nmetadata: {name: other}\nspec: {selector: {matchLabels: {app: api, queue: other}}}\n",
+		"kind: HorizontalPodAutoscaler\nmetadata: {name: api}\nspec: {scaleTargetRef: {kind: Deployment, name: api}, maxReplicas: 4}\n",
+	} {
+		assertNoError(t, m.Add("kube.yaml", strings.NewReader(budget)))
+	}
+	m.Link()
+
+	for i, want := range []struct {
+		Budget     string
+		Autoscaler *Autoscaler
+	}{
+		{"", &Autoscaler{Name: "api", MinReplicas: 1, MaxReplicas: 4}}, // minReplicas defaults to 1
+		{"worker", nil},
+		{"", nil}, // Same name, different kind
+	} {
+		w := m.Workloads[i]
+		assertString(t, w.Budget, want.Budget)
+		if diff := cmp.Diff(w.Autoscaler, want.Autoscaler); diff != "" {
+			t.Errorf("%s %s", w, diff)
+		}
+	}
+}
+
+// Manifests are read from files and directories of the repository
+func TestFetchManifests(t *testing.T) {
+	gh, fake := newFakeGitHub(t, fakeGitHubFixtures)
+
+	t.Run("files in a directory", func(t *testing.T) {
+		m, err := fetchManifests(gh, "GhostGroup/weedmaps", kubePaths)
+		assertNoError(t, err)
+
+		var got []string
+		for _, w := range m.Workloads {
+			got = append(got, w.String()+" "+w.Budget)
+		}
+		if diff := cmp.Diff(got, []string{"Deployment/core-web core-web", "StatefulSet/core-search core-search"}); diff != "" {
+			t.Error(diff)
+		}
+		if diff := cmp.Diff(m.Files, []string{"kube/core.yaml"}); diff != "" {
+			t.Error(diff)
+		}
+	})
+
+	t.Run("only YAML files are read", func(t *testing.T) {
+		before := len(fake.Requests())
+		m, err := fetchManifests(gh, "GhostGroup/ad-server", []string{"deploy"})
+		assertNoError(t, err)
+		if diff := cmp.Diff(m.Files, []string{"deploy/bidder.yaml"}); diff != "" {
+			t.Error(diff)
+		}
+		for _, r := range fake.Requests()[before:] {
+			if strings.HasSuffix(r, "README.md") {
+				t.Errorf("fetched %s", r)
+			}
+		}
+	})
+
+	t.Run("a single file", func(t *testing.T) {
+		m, err := fetchManifests(gh, "GhostGroup/admin", []string{"k8s/admin.yaml"})
+		assertNoError(t, err)
+		assertIDEquals(t, len(m.Workloads), 1)
+		assertIDEquals(t, m.Workloads[0].Line, 4)
+	})
+
+	t.Run("paths come from the environment", func(t *testing.T) {
+		t.Setenv("KUBE_PATHS", "deploy/bidder.yaml")
+		t.Setenv("HELM_VALUES_PATHS", "values.yaml,chart/values.yaml")
+		s := &SvcTestDB{Repo: "GhostGroup/ad-server", GitHub: gh}
+		m, err := s.readManifests()
+		assertNoError(t, err)
+		if diff := cmp.Diff(m.Files, []string{"deploy/bidder.yaml", "chart/values.yaml"}); diff != "" {
+			t.Error(diff)
+		}
+	})
+
+	t.Run("nothing to read", func(t *testing.T) {
+		m, err := fetchManifests(gh, "GhostGroup/weedmaps", []string{"helm", "charts/app.yaml"})
+		assertNoError(t, err)
+		assertIDEquals(t, len(m.Workloads), 0)
+
+		_, err = fetchManifests(gh, "", kubePaths)
+		assertError(t, err, errRepoUnresolved)
+	})
+}
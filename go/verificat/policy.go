@@ -0,0 +1,114 @@
+package main
+
+import (
+	"fmt"
+	"log/slog"
+	"os"
+	"slices"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// LifecycleDeprecated is a service on its way out.
+const LifecycleDeprecated = "deprecated"
+
+// Policy decides which Checks run for a service and which of them are Required,
+// picked by the service's spec.lifecycle and spec.type in Backstage.
+// An experimental service isn't held to everything a production one is.
+type Policy struct {
+	Name      string   `yaml:"name"`
+	Lifecycle string   `yaml:"lifecycle"` // Matches spec.lifecycle, empty matches any
+	Type      string   `yaml:"type"`      // Matches spec.type, empty matches any
+	Checks    []string `yaml:"checks"`    // The IDs of the Checks that run, empty runs every registered Check
+	Required  []string `yaml:"required"`  // The IDs of the Checks in the Required Baseline, unset keeps each Check's own
+}
+
+// Policies are tried in order, the first to match a service is the one it's verified under.
+type Policies []Policy
+
+// defaultPolicy is what a service is verified under when no Policy matches it,
+// e.g.: a System or a Resource, which have no lifecycle.
+var defaultPolicy = Policy{Name: "default"}
+
+// defaultPolicies hold production services to everything,
+// and everything else to having an owner and somewhere its code lives.
+var defaultPolicies = Policies{
+	{Name: "experimental", Lifecycle: LifecycleExperimental, Checks: []string{"codeowners", "owner", "repository"}},
+	{Name: "deprecated", Lifecycle: LifecycleDeprecated, Checks: []string{"owner", "repository"}},
+}
+
+// NewPolicies reads the Policies from the YAML file at POLICY_FILE, a list like testdata/policies.yaml.
+// The file replaces defaultPolicies. Unset or unreadable, the defaults are kept.
+func NewPolicies() Policies {
+	path := fillEnvVar("POLICY_FILE")
+	if path == "ENOENT" {
+		return defaultPolicies
+	}
+
+	policies, err := ReadPolicies(path)
+	if err != nil {
+		slog.Error("Policies could not be loaded", slog.String("Key", "POLICY_FILE"), slog.String("Value", path), slog.Any("Error", err))
+		return defaultPolicies
+	}
+	slog.Info("Policies Loaded", slog.String("Path", path), slog.Int("Policies", len(policies)))
+	return policies
+}
+
+// ReadPolicies reads a list of Policies from a YAML file.
+// Every Policy needs a name, and a Check ID that isn't registered is most likely a typo, so it's an error.
+func ReadPolicies(path string) (Policies, error) {
+	raw, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var policies Policies
+	if err := yaml.Unmarshal(raw, &policies); err != nil {
+		return nil, fmt.Errorf("problem parsing policies, %v", err)
+	}
+	for _, p := range policies {
+		if p.Name == "" {
+			return nil, fmt.Errorf("policy without a name")
+		}
+		for _, id := range slices.Concat(p.Checks, p.Required) {
+			if _, ok := LookupCheck(id); !ok {
+				return nil, fmt.Errorf("policy %q names check %q, which isn't registered", p.Name, id)
+			}
+		}
+	}
+	return policies, nil
+}
+
+// Match returns the first Policy for a service's lifecycle and type, or defaultPolicy.
+func (ps Policies) Match(lifecycle, serviceType string) Policy {
+	for _, p := range ps {
+		if (p.Lifecycle == "" || strings.EqualFold(p.Lifecycle, lifecycle)) &&
+			(p.Type == "" || strings.EqualFold(p.Type, serviceType)) {
+			return p
+		}
+	}
+	return defaultPolicy
+}
+
+// filter keeps the Checks the Policy runs, in the order they were given.
+func (p *Policy) filter(checks []Check) []Check {
+	if p == nil || len(p.Checks) == 0 {
+		return checks
+	}
+	var kept []Check
+	for _, c := range checks {
+		if slices.Contains(p.Checks, c.Info().ID) {
+			kept = append(kept, c)
+		}
+	}
+	return kept
+}
+
+// required is whether a Check is part of the Required Baseline under the Policy.
+func (p *Policy) required(info CheckInfo) bool {
+	if p == nil || p.Required == nil {
+		return info.Required
+	}
+	return slices.Contains(p.Required, info.ID)
+}
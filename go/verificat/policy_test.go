@@ -0,0 +1,109 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+// Policies are matched by lifecycle and type, the first match wins
+func TestPolicies(t *testing.T) {
+	policies, err := ReadPolicies("testdata/policies.yaml")
+	assertError(t, err, nil)
+
+	matchTests := []struct {
+		Lifecycle string
+		Type      string
+		Policy    string
+	}{
+		{"experimental", "service", "experimental"},
+		{"Production", "website", "website"}, // Backstage doesn't insist on lowercase
+		{"production", "service", "production"},
+		{"deprecated", "service", "default"},
+		{"", "", "default"}, // e.g.: a System
+	}
+	for _, tt := range matchTests {
+		t.Run(tt.Lifecycle+"/"+tt.Type, func(t *testing.T) {
+			assertString(t, policies.Match(tt.Lifecycle, tt.Type).Name, tt.Policy)
+		})
+	}
+
+	t.Run("a Policy filters the Checks and decides which are Required", func(t *testing.T) {
+		experimental := policies.Match(LifecycleExperimental, "")
+		var ran []string
+		for _, c := range experimental.filter(RegisteredChecks()) {
+			ran = append(ran, c.Info().ID)
+		}
+		if diff := cmp.Diff(ran, []string{"codeowners", "owner", "repository"}); diff != "" {
+			t.Error(diff)
+		}
+
+		owner, _ := LookupCheck("owner")
+		website := policies.Match(LifecycleProduction, "website")
+		if website.required(owner.Info()) || !experimental.required(owner.Info()) {
+			t.Error("got owner required by the website Policy, or not by the experimental one")
+		}
+	})
+
+	t.Run("without a Policy every Check runs as registered", func(t *testing.T) {
+		var p *Policy
+		if len(p.filter(RegisteredChecks())) != len(RegisteredChecks()) {
+			t.Error("got Checks filtered without a Policy")
+		}
+		owner, _ := LookupCheck("owner")
+		if !p.required(owner.Info()) {
+			t.Error("got owner not required without a Policy")
+		}
+	})
+}
+
+// Policy files are rejected rather than half applied
+func TestReadPolicies(t *testing.T) {
+	badPolicies := map[string]string{
+		"no name":       "- lifecycle: production\n",
+		"unknown check": "- name: typo\n  checks: [ownr]\n",
+		"not a list":    "name: production\n",
+	}
+	for name, content := range badPolicies {
+		t.Run(name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "policies.yaml")
+			assertError(t, os.WriteFile(path, []byte(content), 0o600), nil)
+			if _, err := ReadPolicies(path); err == nil {
+				t.Error("got no error want one")
+			}
+		})
+	}
+
+	t.Run("POLICY_FILE replaces the defaults", func(t *testing.T) {
+		t.Setenv("POLICY_FILE", "testdata/policies.yaml")
+		assertString(t, NewPolicies().Match(LifecycleProduction, "website").Name, "website")
+	})
+
+	t.Run("an unreadable POLICY_FILE keeps the defaults", func(t *testing.T) {
+		t.Setenv("POLICY_FILE", "testdata/nothing-here.yaml")
+		if diff := cmp.Diff(NewPolicies(), defaultPolicies); diff != "" {
+			t.Error(diff)
+		}
+	})
+}
+
+// TestItems runs what the Policy says and grades it by the Policy's Required Baseline
+func TestTestItemsPolicy(t *testing.T) {
+	s := &SvcTestDB{
+		Checks: []Check{repoCheck{}, dependencyCheck{}},
+		Policy: &Policy{Name: "lenient", Checks: []string{"repository"}, Required: []string{}},
+		Score:  100,
+	}
+	result := s.TestItems("component:nowhere")
+
+	assertString(t, result.Policy, "lenient")
+	if len(result.Results) != 1 || result.Results[0].ID != "repository" {
+		t.Fatalf("got %+v want only the repository Check", result.Results)
+	}
+	// The repository is unresolved, but isn't Required under this Policy
+	if result.Status != Degraded || result.Results[0].Required {
+		t.Errorf("got %s, required %t want Degraded and not required", result.Status, result.Results[0].Required)
+	}
+}
@@ -0,0 +1,229 @@
+package main
+
+import (
+	"fmt"
+	"log/slog"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Aggregation is how a System's members are combined into its Rollup.
+// The members are the System's own latest result and those of its Components, Resources and APIs.
+type Aggregation string
+
+const (
+	// AggregateMin takes the lowest Score and the worst Status, a System is only as ready as its least ready part.
+	AggregateMin Aggregation = "min"
+	// AggregateWeightedMean averages the Scores by kind, and the System is only NotReady
+	// once at least half of the weight is. A few unready parts leave it Degraded.
+	AggregateWeightedMean Aggregation = "weighted-mean"
+	// AggregateRequiredAll averages the Scores like weighted-mean,
+	// but every member has to pass the Required Baseline for the System to.
+	// A part that hasn't been verified hasn't passed it.
+	AggregateRequiredAll Aggregation = "required-all"
+)
+
+// rollupWeights are how much each kind counts towards a weighted Score by default.
+// Components are what gets deployed, so they count the most.
+var rollupWeights = map[string]int{
+	KindSystem:    1,
+	KindComponent: 2,
+	KindResource:  1,
+	KindAPI:       1,
+}
+
+// RollupConfig is how Systems are rolled up from their parts.
+type RollupConfig struct {
+	Aggregation Aggregation
+	Weights     map[string]int // By kind, a kind missing here counts once
+}
+
+// NewRollupConfig reads the rollup settings from the environment:
+// ROLLUP_AGGREGATION is min, weighted-mean or required-all,
+// ROLLUP_WEIGHTS is a list of kind=weight, e.g. "component=3,resource=1".
+// Unset or bad values keep the defaults.
+func NewRollupConfig() RollupConfig {
+	config := RollupConfig{Aggregation: AggregateMin, Weights: make(map[string]int)}
+	for kind, weight := range rollupWeights {
+		config.Weights[kind] = weight
+	}
+
+	if value := fillEnvVar("ROLLUP_AGGREGATION"); value != "ENOENT" {
+		aggregation, err := ParseAggregation(value)
+		if err != nil {
+			slog.Error("Environment Variable is not an aggregation", slog.String("Key", "ROLLUP_AGGREGATION"), slog.String("Value", value))
+		} else {
+			config.Aggregation = aggregation
+		}
+	}
+
+	if value := fillEnvVar("ROLLUP_WEIGHTS"); value != "ENOENT" {
+		for _, pair := range strings.Split(value, ",") {
+			kind, w, _ := strings.Cut(strings.TrimSpace(pair), "=")
+			weight, err := strconv.Atoi(w)
+			if err != nil || weight < 0 {
+				slog.Error("Environment Variable has a bad weight", slog.String("Key", "ROLLUP_WEIGHTS"), slog.String("Value", pair))
+				continue
+			}
+			config.Weights[strings.ToLower(kind)] = weight
+		}
+	}
+	return config
+}
+
+// ParseAggregation reads an Aggregation by name.
+func ParseAggregation(name string) (Aggregation, error) {
+	switch a := Aggregation(strings.ToLower(strings.TrimSpace(name))); a {
+	case AggregateMin, AggregateWeightedMean, AggregateRequiredAll:
+		return a, nil
+	}
+	return "", fmt.Errorf("aggregation %q is not one of min, weighted-mean or required-all", name)
+}
+
+// Rollup is a System's readiness derived from its members.
+type Rollup struct {
+	Aggregation Aggregation // How the members were combined
+	Score       int         // The combined Score
+	Status      Readiness   // The combined Status
+	Members     int         // How many verified results went into it
+}
+
+// weight is how much a member counts towards a weighted Score.
+func (c RollupConfig) weight(service WMService) int {
+	kind := service.Kind
+	if kind == "" {
+		kind = KindSystem
+	}
+	if weight, ok := c.Weights[kind]; ok {
+		return weight
+	}
+	return 1
+}
+
+// Apply rolls up the System's view, setting its Rollup.
+// A System with no verified members has no Rollup.
+// Unverified parts have no Score to count, but under required-all they leave the System NotReady.
+func (c RollupConfig) Apply(view *SystemView) {
+	var members []WMService
+	if view.System.LastID > 0 {
+		members = append(members, view.System)
+	}
+	members = append(members, view.Parts...)
+	if len(members) == 0 {
+		view.Rollup = nil
+		return
+	}
+
+	rollup := &Rollup{Aggregation: c.Aggregation, Members: len(members)}
+	switch c.Aggregation {
+	case AggregateWeightedMean:
+		rollup.Score = c.weightedScore(members)
+		rollup.Status = c.weightedStatus(members)
+	case AggregateRequiredAll:
+		rollup.Score = c.weightedScore(members)
+		rollup.Status = worstStatus(members)
+		if len(view.Unverified) > 0 {
+			rollup.Status = NotReady
+		}
+	default:
+		rollup.Aggregation = AggregateMin
+		rollup.Score = members[0].Score
+		for _, m := range members[1:] {
+			rollup.Score = min(rollup.Score, m.Score)
+		}
+		rollup.Status = worstStatus(members)
+	}
+	view.Rollup = rollup
+}
+
+// weightedScore averages the members' Scores by their weights, rounded to the nearest point.
+// If every weight is zero, each member counts once.
+func (c RollupConfig) weightedScore(members []WMService) int {
+	var sum, total float64
+	for _, m := range members {
+		w := float64(c.weight(m))
+		sum += w * float64(m.Score)
+		total += w
+	}
+	if total == 0 {
+		for _, m := range members {
+			sum += float64(m.Score)
+		}
+		total = float64(len(members))
+	}
+	return int(math.Round(sum / total))
+}
+
+// weightedStatus is NotReady once the NotReady members carry at least half of the weight,
+// and Ready only when every member is.
+func (c RollupConfig) weightedStatus(members []WMService) Readiness {
+	var notReady, total int
+	status := Ready
+	for _, m := range members {
+		w := c.weight(m)
+		total += w
+		switch m.Status {
+		case NotReady:
+			notReady += w
+			status = Degraded
+		case Degraded:
+			status = Degraded
+		}
+	}
+	if notReady > 0 && notReady*2 >= total {
+		return NotReady
+	}
+	return status
+}
+
+// worstStatus is NotReady if any member is, then Degraded if any member is.
+// Members stored before Readiness existed have no Status and don't count against it.
+func worstStatus(members []WMService) Readiness {
+	status := Ready
+	for _, m := range members {
+		switch m.Status {
+		case NotReady:
+			return NotReady
+		case Degraded:
+			status = Degraded
+		}
+	}
+	return status
+}
+
+// Systems gathers every System in the Almanac into a tree with its parts, sorted by name.
+// Systems known only through their parts are included,
+// along with the catalog's Systems that any verified part belongs to.
+func (l Almanac) Systems(g *DependencyGraph) []SystemView {
+	names := make(map[string]bool)
+	for _, service := range l {
+		switch {
+		case service.Kind == "" || service.Kind == KindSystem:
+			names[service.Name] = true
+		case service.System != "":
+			names[service.System] = true
+		}
+	}
+	if g != nil {
+		for _, system := range g.systems() {
+			for _, part := range g.partsOf(system) {
+				if l.Find(part) != nil {
+					names[system] = true
+					break
+				}
+			}
+		}
+	}
+
+	views := make([]SystemView, 0, len(names))
+	for name := range names {
+		view, _ := l.SystemView(name, g)
+		views = append(views, view)
+	}
+	sort.Slice(views, func(i, j int) bool {
+		return views[i].System.Name < views[j].System.Name
+	})
+	return views
+}
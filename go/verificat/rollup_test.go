@@ -0,0 +1,186 @@
+package main
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+// A System's readiness comes from its own result and its parts'
+func TestRollup(t *testing.T) {
+	view := SystemView{
+		System: WMService{Name: "core", Kind: KindSystem, LastID: 4, Score: 100, Status: Ready},
+		Parts: Almanac{
+			{Name: "component:core-braze", Kind: KindComponent, System: "core", LastID: 1, Score: 90, Status: NotReady},
+			{Name: "resource:core-db", Kind: KindResource, System: "core", LastID: 2, Score: 99, Status: Degraded},
+			{Name: "api:core-api", Kind: KindAPI, System: "core", LastID: 1, Score: 100, Status: Ready},
+		},
+	}
+
+	rollupTests := []struct {
+		Name        string
+		Aggregation Aggregation
+		Want        Rollup
+	}{
+		// Only as ready as the least ready part
+		{"min", AggregateMin, Rollup{AggregateMin, 90, NotReady, 4}},
+		// (100 + 2*90 + 99 + 100) / 5, and the unready Component is under half the weight
+		{"weighted mean", AggregateWeightedMean, Rollup{AggregateWeightedMean, 96, Degraded, 4}},
+		// The same Score, but every part has to pass the Required Baseline
+		{"required all", AggregateRequiredAll, Rollup{AggregateRequiredAll, 96, NotReady, 4}},
+	}
+
+	for _, tt := range rollupTests {
+		t.Run(tt.Name, func(t *testing.T) {
+			v := view
+			RollupConfig{Aggregation: tt.Aggregation, Weights: rollupWeights}.Apply(&v)
+			if diff := cmp.Diff(v.Rollup, &tt.Want); diff != "" {
+				t.Error(diff)
+			}
+		})
+	}
+
+	t.Run("weighted mean is NotReady once half the weight is", func(t *testing.T) {
+		v := SystemView{
+			System: WMService{Name: "core", LastID: 4, Score: 100, Status: Ready},
+			Parts:  Almanac{{Name: "component:core-braze", Kind: KindComponent, System: "core", LastID: 1, Score: 90, Status: NotReady}},
+		}
+		RollupConfig{Aggregation: AggregateWeightedMean, Weights: rollupWeights}.Apply(&v)
+		if v.Rollup.Status != NotReady || v.Rollup.Score != 93 {
+			t.Errorf("got %+v want 93 NotReady", v.Rollup)
+		}
+	})
+
+	t.Run("zero weights count each member once", func(t *testing.T) {
+		v := view
+		RollupConfig{Aggregation: AggregateWeightedMean, Weights: map[string]int{KindSystem: 0, KindComponent: 0, KindResource: 0, KindAPI: 0}}.Apply(&v)
+		assertIDEquals(t, v.Rollup.Score, 97)
+	})
+
+	t.Run("an unverified System rolls up from its parts alone", func(t *testing.T) {
+		v := SystemView{
+			System: WMService{Name: "ad-server", Kind: KindSystem},
+			Parts:  Almanac{{Name: "api:ad-server-api", Kind: KindAPI, System: "ad-server", LastID: 2, Score: 98, Status: Degraded}},
+		}
+		RollupConfig{Aggregation: AggregateMin}.Apply(&v)
+		if diff := cmp.Diff(v.Rollup, &Rollup{AggregateMin, 98, Degraded, 1}); diff != "" {
+			t.Error(diff)
+		}
+	})
+
+	t.Run("nothing verified has no rollup", func(t *testing.T) {
+		v := SystemView{System: WMService{Name: "admin", Kind: KindSystem}}
+		RollupConfig{Aggregation: AggregateMin}.Apply(&v)
+		if v.Rollup != nil {
+			t.Errorf("got %+v want no rollup", v.Rollup)
+		}
+	})
+}
+
+func TestNewRollupConfig(t *testing.T) {
+	t.Run("defaults", func(t *testing.T) {
+		config := NewRollupConfig()
+		if config.Aggregation != AggregateMin || config.Weights[KindComponent] != 2 {
+			t.Errorf("got %+v want min with the default weights", config)
+		}
+	})
+
+	t.Run("from the environment", func(t *testing.T) {
+		t.Setenv("ROLLUP_AGGREGATION", "Weighted-Mean")
+		t.Setenv("ROLLUP_WEIGHTS", "component=5, api=0, resource=lots")
+
+		config := NewRollupConfig()
+		want := map[string]int{KindSystem: 1, KindComponent: 5, KindResource: 1, KindAPI: 0}
+		if config.Aggregation != AggregateWeightedMean {
+			t.Errorf("got %q want %q", config.Aggregation, AggregateWeightedMean)
+		}
+		if diff := cmp.Diff(config.Weights, want); diff != "" {
+			t.Error(diff)
+		}
+	})
+
+	t.Run("a bad aggregation keeps the default", func(t *testing.T) {
+		t.Setenv("ROLLUP_AGGREGATION", "median")
+		if config := NewRollupConfig(); config.Aggregation != AggregateMin {
+			t.Errorf("got %q want %q", config.Aggregation, AggregateMin)
+		}
+	})
+}
+
+// Every System is gathered with its parts, including Systems only known through their parts
+func TestAlmanacSystems(t *testing.T) {
+	almanac := Almanac{
+		{Name: "core", LastID: 3, Score: 100},
+		{Name: "component:core-braze", Kind: KindComponent, System: "core", LastID: 1, Score: 98},
+		{Name: "api:ad-server-api", Kind: KindAPI, System: "ad-server", LastID: 2, Score: 99},
+		{Name: "resource:lost", Kind: KindResource, LastID: 1, Score: 99},
+		{Name: "admin", Kind: KindSystem, LastID: 1, Score: 99},
+	}
+
+	var got []string
+	for _, view := range almanac.Systems(nil) {
+		got = append(got, view.System.Name)
+		for _, part := range view.Parts {
+			got = append(got, "  "+part.Name)
+		}
+	}
+	want := []string{"ad-server", "  api:ad-server-api", "admin", "core", "  component:core-braze"}
+	if diff := cmp.Diff(got, want); diff != "" {
+		t.Error(diff)
+	}
+}
+
+// A System's parts come from the catalog, so the ones never verified are reported too
+func TestAlmanacSystemsFromCatalog(t *testing.T) {
+	c, err := NewBackstageCatalog(newFakeBackstage(t, fakeBackstageFixtures))
+	assertError(t, err, nil)
+	g, err := BuildDependencyGraph(c)
+	assertError(t, err, nil)
+
+	// The bidder has never been verified, and the cache builder was stored without its System
+	almanac := Almanac{
+		{Name: "component:ad-server-cache-builder", Kind: KindComponent, LastID: 1, Score: 98, Status: Ready},
+		{Name: "api:ad-server-api", Kind: KindAPI, System: "ad-server", LastID: 2, Score: 99, Status: Ready},
+	}
+
+	view, ok := almanac.SystemView("ad-server", g)
+	if !ok {
+		t.Fatal("got no view of ad-server")
+	}
+	var parts []string
+	for _, part := range view.Parts {
+		parts = append(parts, part.Name)
+	}
+	if diff := cmp.Diff(parts, []string{"api:ad-server-api", "component:ad-server-cache-builder"}); diff != "" {
+		t.Error(diff)
+	}
+	if diff := cmp.Diff(view.Unverified, []string{"component:ad-server-bidder"}); diff != "" {
+		t.Error(diff)
+	}
+
+	rollupTests := []struct {
+		Aggregation Aggregation
+		Want        Rollup
+	}{
+		{AggregateMin, Rollup{AggregateMin, 98, Ready, 2}},
+		// The bidder hasn't passed the Required Baseline
+		{AggregateRequiredAll, Rollup{AggregateRequiredAll, 98, NotReady, 2}},
+	}
+	for _, tt := range rollupTests {
+		v := view
+		RollupConfig{Aggregation: tt.Aggregation, Weights: rollupWeights}.Apply(&v)
+		if diff := cmp.Diff(v.Rollup, &tt.Want); diff != "" {
+			t.Errorf("%s %s", tt.Aggregation, diff)
+		}
+	}
+
+	t.Run("a System is gathered through a part stored without it", func(t *testing.T) {
+		var names []string
+		for _, view := range almanac[:1].Systems(g) {
+			names = append(names, view.System.Name)
+		}
+		if diff := cmp.Diff(names, []string{"ad-server"}); diff != "" {
+			t.Error(diff)
+		}
+	})
+}
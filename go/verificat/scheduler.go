@@ -0,0 +1,226 @@
+package main
+
+import (
+	"context"
+	"log/slog"
+	"math/rand/v2"
+	"strconv"
+	"sync"
+	"time"
+)
+
+const (
+	scheduleInterval    = 24 * time.Hour   // Time between rounds over the whole catalog
+	scheduleJitter      = 15 * time.Minute // Up to this much is added to each wait, so rounds don't line up
+	scheduleConcurrency = 2                // Scheduled verifications in flight at once
+	scheduleJobTimeout  = 10 * time.Minute // Longest to wait on one scheduled verification
+)
+
+// ScheduleConfig is how often, and how hard, the Scheduler works the catalog.
+type ScheduleConfig struct {
+	Interval    time.Duration // Zero turns the Scheduler off
+	Jitter      time.Duration
+	Concurrency int
+}
+
+// NewScheduleConfig reads the Scheduler settings from the environment:
+// SCHEDULE_INTERVAL and SCHEDULE_JITTER are durations (e.g. "6h", "90s"),
+// SCHEDULE_CONCURRENCY is a count. Unset or bad values keep the defaults.
+func NewScheduleConfig() ScheduleConfig {
+	return ScheduleConfig{
+		Interval:    envDuration("SCHEDULE_INTERVAL", scheduleInterval),
+		Jitter:      envDuration("SCHEDULE_JITTER", scheduleJitter),
+		Concurrency: envCount("SCHEDULE_CONCURRENCY", scheduleConcurrency),
+	}
+}
+
+// SchedulerState is what the Scheduler shows on /admin/scheduler.
+// Times are Unix Epoch in seconds, the counts are for the latest round.
+type SchedulerState struct {
+	Interval    string // How often a round starts
+	Jitter      string // Most added to each wait
+	Concurrency int    // Verifications in flight at once
+	Running     bool   // A round is underway
+	Rounds      int    // Rounds started since the server started
+	LastStarted int64  `json:",omitempty"`
+	LastDone    int64  `json:",omitempty"`
+	NextRound   int64  `json:",omitempty"`
+	LastError   string `json:",omitempty"` // Why the catalog couldn't be read
+	Systems     int    // Systems found in the catalog
+	Finished    int    // Verifications that finished
+	Failed      int    // Verifications that failed
+	Rejected    int    // Verifications the job queue turned away, they're tried again next round
+}
+
+// DiscoverFunc lists every System in the catalog.
+type DiscoverFunc func() ([]string, error)
+
+// Scheduler is the autonomous part of Verificat.
+// On every interval it discovers the whole catalog and queues a verification for each System.
+type Scheduler struct {
+	mu       sync.Mutex
+	config   ScheduleConfig
+	discover DiscoverFunc
+	jobs     *JobQueue
+	state    SchedulerState
+}
+
+// NewScheduler Constructor
+// Verifications are queued on /jobs/, shared with the API.
+func NewScheduler(config ScheduleConfig, discover DiscoverFunc, jobs *JobQueue) *Scheduler {
+	if config.Concurrency < 1 {
+		config.Concurrency = 1
+	}
+
+	return &Scheduler{
+		config:   config,
+		discover: discover,
+		jobs:     jobs,
+		state: SchedulerState{
+			Interval:    config.Interval.String(),
+			Jitter:      config.Jitter.String(),
+			Concurrency: config.Concurrency,
+		},
+	}
+}
+
+// Run works the catalog one round at a time until the context is done.
+// The first round starts after the jitter alone, so a fresh start gets going right away.
+func (s *Scheduler) Run(ctx context.Context) {
+	wait := s.jitter()
+	for {
+		s.update(func(st *SchedulerState) { st.NextRound = time.Now().Add(wait).Unix() })
+
+		select {
+		case <-ctx.Done():
+			slog.Info("Scheduler Stopped")
+			return
+		case <-time.After(wait):
+		}
+
+		s.round()
+		wait = s.config.Interval + s.jitter()
+	}
+}
+
+// State returns a snapshot of the Scheduler.
+func (s *Scheduler) State() SchedulerState {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.state
+}
+
+// round queues a verification of every System,
+// keeping no more than Concurrency of them in flight.
+func (s *Scheduler) round() {
+	s.update(func(st *SchedulerState) {
+		st.Running = true
+		st.Rounds++
+		st.LastStarted = time.Now().Unix()
+		st.NextRound = 0
+		st.LastError = ""
+		st.Systems, st.Finished, st.Failed, st.Rejected = 0, 0, 0, 0
+	})
+	defer s.update(func(st *SchedulerState) {
+		st.Running = false
+		st.LastDone = time.Now().Unix()
+	})
+
+	systems, err := s.discover()
+	if err != nil {
+		slog.Error("Scheduler Failed to read the catalog", slog.Any("Error", err))
+		s.update(func(st *SchedulerState) { st.LastError = err.Error() })
+		return
+	}
+	slog.Info("Scheduler Round Started", slog.Int("Systems", len(systems)))
+
+	inFlight := make(chan struct{}, s.config.Concurrency)
+	var wg sync.WaitGroup
+	for _, system := range systems {
+		if system == "" {
+			continue
+		}
+		s.update(func(st *SchedulerState) { st.Systems++ })
+
+		inFlight <- struct{}{}
+		wg.Add(1)
+		go func(system string) {
+			defer wg.Done()
+			defer func() { <-inFlight }()
+			s.verify(system)
+		}(system)
+	}
+	wg.Wait()
+
+	state := s.State()
+	slog.Info("Scheduler Round Done",
+		slog.Int("Systems", state.Systems),
+		slog.Int("Finished", state.Finished),
+		slog.Int("Failed", state.Failed),
+		slog.Int("Rejected", state.Rejected),
+	)
+}
+
+// verify queues one System and waits for its job, counting how it went.
+func (s *Scheduler) verify(system string) {
+	job, err := s.jobs.Submit(system)
+	if err != nil {
+		s.update(func(st *SchedulerState) { st.Rejected++ })
+		return
+	}
+
+	s.jobs.Wait(job.ID, scheduleJobTimeout)
+	done, _ := s.jobs.Get(job.ID)
+	s.update(func(st *SchedulerState) {
+		if done.Status == JobFinished {
+			st.Finished++
+		} else {
+			st.Failed++
+		}
+	})
+}
+
+// update changes the state while holding the lock.
+func (s *Scheduler) update(change func(st *SchedulerState)) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	change(&s.state)
+}
+
+// jitter picks a random extra wait, up to the configured Jitter.
+func (s *Scheduler) jitter() time.Duration {
+	if s.config.Jitter <= 0 {
+		return 0
+	}
+	return rand.N(s.config.Jitter)
+}
+
+// envDuration reads a duration from an Environment Variable,
+// falling back to /def/ if it's unset or can't be parsed.
+func envDuration(ev string, def time.Duration) time.Duration {
+	value := fillEnvVar(ev)
+	if value == "ENOENT" {
+		return def
+	}
+	d, err := time.ParseDuration(value)
+	if err != nil || d < 0 {
+		slog.Error("Environment Variable is not a duration", slog.String("Key", ev), slog.String("Value", value))
+		return def
+	}
+	return d
+}
+
+// envCount reads a positive count from an Environment Variable,
+// falling back to /def/ if it's unset or can't be parsed.
+func envCount(ev string, def int) int {
+	value := fillEnvVar(ev)
+	if value == "ENOENT" {
+		return def
+	}
+	n, err := strconv.Atoi(value)
+	if err != nil || n < 1 {
+		slog.Error("Environment Variable is not a count", slog.String("Key", ev), slog.String("Value", value))
+		return def
+	}
+	return n
+}
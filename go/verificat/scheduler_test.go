@@ -0,0 +1,189 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestScheduler(t *testing.T) {
+	t.Run("a round verifies every system in the catalog", func(t *testing.T) {
+		var mu sync.Mutex
+		verified := map[string]int{}
+		jobs := NewJobQueue(4, 10, func(service string) (*VerifyResult, error) {
+			mu.Lock()
+			defer mu.Unlock()
+			verified[service]++
+			if service == "broken" {
+				return nil, fmt.Errorf("no catalog entry for %s", service)
+			}
+			return &VerifyResult{Service: service}, nil
+		})
+		discover := func() ([]string, error) { return []string{"admin", "core", "", "broken"}, nil }
+
+		s := NewScheduler(ScheduleConfig{Interval: time.Hour, Concurrency: 2}, discover, jobs)
+		s.round()
+
+		for _, service := range []string{"admin", "core", "broken"} {
+			assertIDEquals(t, verified[service], 1)
+		}
+
+		got := s.State()
+		want := SchedulerState{Interval: "1h0m0s", Jitter: "0s", Concurrency: 2, Rounds: 1, Systems: 3, Finished: 2, Failed: 1}
+		got.LastStarted, got.LastDone = 0, 0
+		if got != want {
+			t.Errorf("got state %+v want %+v", got, want)
+		}
+	})
+
+	t.Run("no more than Concurrency verifications at once", func(t *testing.T) {
+		var mu sync.Mutex
+		running, most := 0, 0
+		jobs := NewJobQueue(8, 50, func(service string) (*VerifyResult, error) {
+			mu.Lock()
+			running++
+			most = max(most, running)
+			mu.Unlock()
+
+			time.Sleep(2 * time.Millisecond)
+
+			mu.Lock()
+			running--
+			mu.Unlock()
+			return &VerifyResult{Service: service}, nil
+		})
+		discover := func() ([]string, error) {
+			var systems []string
+			for i := 0; i < 20; i++ {
+				systems = append(systems, fmt.Sprintf("svc-%d", i))
+			}
+			return systems, nil
+		}
+
+		s := NewScheduler(ScheduleConfig{Interval: time.Hour, Concurrency: 3}, discover, jobs)
+		s.round()
+
+		if most > 3 {
+			t.Errorf("got %d verifications at once want at most 3", most)
+		}
+		assertIDEquals(t, s.State().Finished, 20)
+	})
+
+	t.Run("a full job queue is counted and the round carries on", func(t *testing.T) {
+		release := make(chan struct{})
+		jobs := NewJobQueue(1, 1, func(service string) (*VerifyResult, error) {
+			<-release
+			return &VerifyResult{Service: service}, nil
+		})
+		// Fill the only worker and the queue
+		jobs.Submit("busy")
+		for i := 0; i < 1000; i++ {
+			if job, _ := jobs.Get(1); job.Status == JobRunning {
+				break
+			}
+			time.Sleep(time.Millisecond)
+		}
+		jobs.Submit("busy")
+		discover := func() ([]string, error) { return []string{"admin"}, nil }
+
+		s := NewScheduler(ScheduleConfig{Interval: time.Hour, Concurrency: 1}, discover, jobs)
+		s.round()
+		close(release)
+
+		assertIDEquals(t, s.State().Rejected, 1)
+	})
+
+	t.Run("catalog errors are shown on the state", func(t *testing.T) {
+		jobs := NewJobQueue(1, 1, nil)
+		discover := func() ([]string, error) { return nil, fmt.Errorf("environment variable BACKSTAGE not set") }
+
+		s := NewScheduler(ScheduleConfig{Interval: time.Hour}, discover, jobs)
+		s.round()
+
+		got := s.State()
+		if got.LastError != "environment variable BACKSTAGE not set" || got.Running {
+			t.Errorf("got state %+v want the catalog error", got)
+		}
+	})
+
+	t.Run("runs rounds on the interval until stopped", func(t *testing.T) {
+		jobs := NewJobQueue(1, 10, func(service string) (*VerifyResult, error) {
+			return &VerifyResult{Service: service}, nil
+		})
+		rounds := make(chan struct{}, 10)
+		discover := func() ([]string, error) {
+			rounds <- struct{}{}
+			return []string{"admin"}, nil
+		}
+
+		s := NewScheduler(ScheduleConfig{Interval: time.Millisecond, Jitter: time.Millisecond, Concurrency: 1}, discover, jobs)
+		ctx, stop := context.WithCancel(context.Background())
+		done := make(chan struct{})
+		go func() {
+			s.Run(ctx)
+			close(done)
+		}()
+
+		for i := 0; i < 3; i++ {
+			select {
+			case <-rounds:
+			case <-time.After(time.Second):
+				t.Fatalf("round %d never started", i+1)
+			}
+		}
+		stop()
+		<-done
+
+		if got := s.State().Rounds; got < 3 {
+			t.Errorf("got %d rounds want at least 3", got)
+		}
+	})
+}
+
+func TestScheduleConfig(t *testing.T) {
+	t.Run("defaults when unset", func(t *testing.T) {
+		t.Setenv("SCHEDULE_INTERVAL", "")
+		t.Setenv("SCHEDULE_JITTER", "")
+		t.Setenv("SCHEDULE_CONCURRENCY", "")
+
+		got := NewScheduleConfig()
+		want := ScheduleConfig{Interval: scheduleInterval, Jitter: scheduleJitter, Concurrency: scheduleConcurrency}
+		if got != want {
+			t.Errorf("got %+v want %+v", got, want)
+		}
+	})
+
+	t.Run("reads the environment", func(t *testing.T) {
+		t.Setenv("SCHEDULE_INTERVAL", "6h")
+		t.Setenv("SCHEDULE_JITTER", "90s")
+		t.Setenv("SCHEDULE_CONCURRENCY", "8")
+
+		got := NewScheduleConfig()
+		want := ScheduleConfig{Interval: 6 * time.Hour, Jitter: 90 * time.Second, Concurrency: 8}
+		if got != want {
+			t.Errorf("got %+v want %+v", got, want)
+		}
+	})
+
+	t.Run("bad values keep the defaults", func(t *testing.T) {
+		t.Setenv("SCHEDULE_INTERVAL", "daily")
+		t.Setenv("SCHEDULE_JITTER", "-1m")
+		t.Setenv("SCHEDULE_CONCURRENCY", "0")
+
+		got := NewScheduleConfig()
+		want := ScheduleConfig{Interval: scheduleInterval, Jitter: scheduleJitter, Concurrency: scheduleConcurrency}
+		if got != want {
+			t.Errorf("got %+v want %+v", got, want)
+		}
+	})
+
+	t.Run("a zero interval turns the scheduler off", func(t *testing.T) {
+		t.Setenv("SCHEDULE_INTERVAL", "0")
+
+		if got := NewScheduleConfig().Interval; got != 0 {
+			t.Errorf("got interval %v want 0", got)
+		}
+	})
+}
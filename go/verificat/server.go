@@ -5,7 +5,10 @@ import (
 	"fmt"
 	"log/slog"
 	"net/http"
+	"strconv"
 	"strings"
+	"sync"
+	"time"
 )
 
 const (
@@ -13,6 +16,7 @@ const (
 	jsonContentType = "application/json"
 	htmlTemplates   = "templates/*gohtml"
 	targetDocTmpl   = "almanac.gohtml"
+	catalogGraphTTL = 5 * time.Minute // How long the System views reuse the catalog before reading it again
 )
 
 // WMService defines the service and its final checklist score
@@ -21,21 +25,36 @@ const (
 // then the score is 0, and this value remains 100.
 // If the checklist comes back as false,
 // then the score is 1, and this value becomes 99.
+// Principles breaks the same score down across the Eight Principles.
+// Status is the Required Baseline gate, which the score can't buy back.
 type WMService struct {
-	Name   string // Weedmaps Service Name
-	LastID int    // The last test ID
-	Score  int    // The current score (100 - score)
+	Name       string          // Weedmaps Service Name, kind:name for anything but a System
+	Kind       string          `json:",omitempty"` // The entity kind, empty for Systems stored before other kinds were verified
+	System     string          `json:",omitempty"` // The System a Component, Resource or API belongs to
+	LastID     int             // The last test ID
+	Score      int             // The current score (100 - score)
+	Status     Readiness       `json:",omitempty"` // Ready, Degraded or NotReady
+	Principles PrincipleScores `json:",omitempty"` // The current score per Principle
 }
 
 type ServiceStore interface {
-	GetTriggerID(name string) int     // Retrieve the count of tests done
-	TriggerID(name string, score int) // The current run ID, its score
-	GetAlmanac() Almanac              // A collection of all services and their scores
+	GetTriggerID(name string) int                              // Retrieve the count of tests done
+	TriggerID(name string, result *VerifyResult)               // Store a result, stamping it with its run ID
+	GetAlmanac() Almanac                                       // A collection of all services and their scores
+	GetHistory(name string, from, to time.Time) []VerifyResult // Every run for a service within a time range
 }
 
 // VerificationServ needs to reference the interface to use it
+// Verifications run in the background on /jobs/.
+// The /scheduler/ is only set when the catalog is re-verified on its own.
 type VerificationServ struct {
-	store ServiceStore
+	store     ServiceStore
+	jobs      *JobQueue
+	scheduler *Scheduler
+	github    *GitHubClient
+	rollup    RollupConfig
+	policies  Policies
+	catalog   *catalogCache
 	http.Handler
 }
 
@@ -43,15 +62,25 @@ type VerificationServ struct {
 func NewVerificationServ(store ServiceStore) *VerificationServ {
 	v := new(VerificationServ)
 	v.store = store
+	v.github = defaultGitHub
+	v.rollup = NewRollupConfig()
+	v.policies = NewPolicies()
+	v.catalog = &catalogCache{read: catalogGraph, now: time.Now}
+	v.jobs = NewJobQueue(jobWorkers, jobQueueDepth, v.verify)
 
 	// This will be assigned to the http.Handler in PlayerServer
 	// so that the routing is done once at the start, not on every request.
 	router := http.NewServeMux()
 
 	// Set up each server endpoint and its associated handler function
+	router.Handle("/admin/scheduler", http.HandlerFunc(v.schedulerHandler))
 	router.Handle("/almanac", http.HandlerFunc(v.almanacHandler))
 	router.Handle("/healthz", http.HandlerFunc(v.healthzHandler))
 	router.Handle("/v0/almanac", http.HandlerFunc(v.almanacHandler))
+	router.Handle("/v0/dependencies/", http.HandlerFunc(v.dependenciesHandler))
+	router.Handle("/v0/history/", http.HandlerFunc(v.historyHandler))
+	router.Handle("/v0/jobs/", http.HandlerFunc(v.jobsHandler))
+	router.Handle("/v0/systems/", http.HandlerFunc(v.systemsHandler))
 	router.Handle("/v0/", http.HandlerFunc(v.servicesHandler))
 	router.Handle("/", http.HandlerFunc(v.homeHandler))
 
@@ -71,6 +100,18 @@ func (p *VerificationServ) healthzHandler(w http.ResponseWriter, r *http.Request
 	w.Write([]byte(`ok`))
 }
 
+// Scheduler admin handler (/admin/scheduler)
+// Shows how the Scheduler is configured and how its latest round went.
+func (p *VerificationServ) schedulerHandler(w http.ResponseWriter, r *http.Request) {
+	if p.scheduler == nil {
+		http.Error(w, "The scheduler is off, SCHEDULE_INTERVAL is 0. Unset it or set a duration, e.g.: 24h, to turn it on.", http.StatusNotFound)
+		return
+	}
+
+	w.Header().Set("content-type", jsonContentType)
+	json.NewEncoder(w).Encode(p.scheduler.State())
+}
+
 // UI homepage handler
 // Render the current full Almanac to the home page
 func (p *VerificationServ) homeHandler(w http.ResponseWriter, r *http.Request) {
@@ -82,10 +123,15 @@ func (p *VerificationServ) homeHandler(w http.ResponseWriter, r *http.Request) {
 	// Create a full dataset to work with
 	// This is where BuildSVG needs to operate first
 	currAlmanac := p.store.GetAlmanac()
+	systems := currAlmanac.Systems(p.catalog.Graph())
+	for i := range systems {
+		p.rollup.Apply(&systems[i])
+	}
 	aWeb := &AlmanacWeb{
 		Title:     "Verificat | weedmaps production readiness scores",
 		Content:   BuildSVG(&currAlmanac, sc),
 		FullScore: currAlmanac,
+		Systems:   systems,
 	}
 
 	if err := RenderWeb(w, aWeb, htmlTemplates, targetDocTmpl); err != nil {
@@ -113,6 +159,190 @@ func (p *VerificationServ) almanacHandler(w http.ResponseWriter, r *http.Request
 	)
 }
 
+// Service history handler
+// Version 0 (/v0/history/<SERVICE>?from=<TIME>&to=<TIME>)
+// Return every stored run for a service as JSON, oldest first.
+// Times can be Unix seconds or RFC3339, and either end of the range can be left open.
+func (p *VerificationServ) historyHandler(w http.ResponseWriter, r *http.Request) {
+	service := strings.TrimPrefix(r.URL.Path, "/v0/history/")
+
+	from, err := parseTimeParam(r.URL.Query().Get("from"))
+	if err != nil {
+		http.Error(w, fmt.Sprintf("bad 'from' time: %v", err), http.StatusBadRequest)
+		return
+	}
+	to, err := parseTimeParam(r.URL.Query().Get("to"))
+	if err != nil {
+		http.Error(w, fmt.Sprintf("bad 'to' time: %v", err), http.StatusBadRequest)
+		return
+	}
+
+	// Always answer with a JSON array, even when there's nothing in range
+	runs := p.store.GetHistory(service, from, to)
+	if runs == nil {
+		runs = []VerifyResult{}
+	}
+
+	w.Header().Set("content-type", jsonContentType)
+	json.NewEncoder(w).Encode(runs)
+	slog.Info("History API",
+		slog.String("Method", r.Method),
+		slog.String("Path", r.URL.Path),
+		slog.Int64("ContentLength", r.ContentLength),
+		slog.String("Remote", r.RemoteAddr),
+	)
+}
+
+// System view handler
+// Version 0 (/v0/systems/<SYSTEM>?aggregation=<min|weighted-mean|required-all>)
+// Return a System's latest result with the latest results of its Components, Resources and APIs,
+// and its Rollup from all of them. Without a System, every System is returned as a tree.
+// The aggregation defaults to ROLLUP_AGGREGATION.
+func (p *VerificationServ) systemsHandler(w http.ResponseWriter, r *http.Request) {
+	system := strings.TrimPrefix(r.URL.Path, "/v0/systems/")
+
+	rollup := p.rollup
+	if a := r.URL.Query().Get("aggregation"); a != "" {
+		aggregation, err := ParseAggregation(a)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		rollup.Aggregation = aggregation
+	}
+
+	almanac := p.store.GetAlmanac()
+	graph := p.catalog.Graph()
+	var answer any
+	if system == "" {
+		views := almanac.Systems(graph)
+		for i := range views {
+			rollup.Apply(&views[i])
+		}
+		answer = views
+	} else {
+		view, ok := almanac.SystemView(system, graph)
+		if !ok {
+			http.Error(w, fmt.Sprintf("No record found for System %q or any of its parts.", system), http.StatusNotFound)
+			return
+		}
+		rollup.Apply(&view)
+		answer = view
+	}
+
+	w.Header().Set("content-type", jsonContentType)
+	json.NewEncoder(w).Encode(answer)
+	slog.Info("Systems API",
+		slog.String("Method", r.Method),
+		slog.String("Path", r.URL.Path),
+		slog.Int64("ContentLength", r.ContentLength),
+		slog.String("Remote", r.RemoteAddr),
+	)
+}
+
+// catalogGraph reads the catalog for the System views, which take their parts from it.
+// Without a catalog they're gathered from the Almanac alone.
+func catalogGraph() *DependencyGraph {
+	url := fillEnvVar("BACKSTAGE")
+	if url == "ENOENT" {
+		return nil
+	}
+	catalog, err := NewBackstageCatalog(url)
+	if err != nil {
+		slog.Warn("Catalog Unavailable", slog.Any("Error", err))
+		return nil
+	}
+	graph, err := BuildDependencyGraph(catalog)
+	if err != nil {
+		return nil
+	}
+	return graph
+}
+
+// catalogCache keeps the catalog graph between requests, so a page view doesn't read the whole catalog.
+// It's read again once it's older than catalogGraphTTL.
+type catalogCache struct {
+	read func() *DependencyGraph
+	now  func() time.Time
+
+	mu    sync.Mutex
+	graph *DependencyGraph
+	until time.Time
+}
+
+// Graph returns the catalog graph, reading the catalog if the one kept is missing or stale.
+// A failed read keeps the last graph until the next try.
+func (c *catalogCache) Graph() *DependencyGraph {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.now().Before(c.until) {
+		return c.graph
+	}
+	if graph := c.read(); graph != nil {
+		c.graph = graph
+	}
+	c.until = c.now().Add(catalogGraphTTL)
+	return c.graph
+}
+
+// Dependency report handler
+// Version 0 (/v0/dependencies/<SERVICE>)
+// Return every dependency of a service from the catalog with its latest readiness,
+// listing the ones below the Required Baseline.
+func (p *VerificationServ) dependenciesHandler(w http.ResponseWriter, r *http.Request) {
+	ref, err := ParseEntityRef(strings.TrimPrefix(r.URL.Path, "/v0/dependencies/"))
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	envVar := "BACKSTAGE"
+	url := fillEnvVar(envVar)
+	if url == "ENOENT" {
+		slog.Error("Environment Variable not set", slog.String("Key", envVar), slog.String("Value", url))
+		http.Error(w, fmt.Sprintf("environment variable %s not set", envVar), http.StatusServiceUnavailable)
+		return
+	}
+	catalog, err := NewBackstageCatalog(url)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusServiceUnavailable)
+		return
+	}
+	graph, err := BuildDependencyGraph(catalog)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadGateway)
+		return
+	}
+
+	report, ok := graph.Report(ref.String(), p.store.GetAlmanac())
+	if !ok {
+		http.Error(w, fmt.Sprintf("No entity %q found in the catalog.", ref), http.StatusNotFound)
+		return
+	}
+
+	w.Header().Set("content-type", jsonContentType)
+	json.NewEncoder(w).Encode(report)
+	slog.Info("Dependencies API",
+		slog.String("Method", r.Method),
+		slog.String("Path", r.URL.Path),
+		slog.Int64("ContentLength", r.ContentLength),
+		slog.String("Remote", r.RemoteAddr),
+	)
+}
+
+// parseTimeParam reads a query time as Unix seconds or RFC3339.
+// An empty value is the zero time, which leaves that end of a range open.
+func parseTimeParam(v string) (time.Time, error) {
+	if v == "" {
+		return time.Time{}, nil
+	}
+	if secs, err := strconv.ParseInt(v, 10, 64); err == nil {
+		return time.Unix(secs, 0), nil
+	}
+	return time.Parse(time.RFC3339, v)
+}
+
 // API for service tests handler
 // Version 0 (/v0/<SERVICE>)
 func (p *VerificationServ) servicesHandler(w http.ResponseWriter, r *http.Request) {
@@ -123,7 +353,7 @@ func (p *VerificationServ) servicesHandler(w http.ResponseWriter, r *http.Reques
 	// These methods on VerificationServ can pass the handler interfaces around
 	switch r.Method {
 	case http.MethodPost:
-		// Kick off the test and display the results
+		// Queue the test and point at where its results will be
 		p.runVerification(w, service)
 	case http.MethodGet:
 		// Get last session ID from the database.
@@ -150,18 +380,66 @@ func (p *VerificationServ) showLastID(w http.ResponseWriter, service string) {
 	fmt.Fprintf(w, "LastID for "+service+": %d\n", lastID)
 }
 
-// runVerification. Takes a passed configuration and launches testing.
+// runVerification. Queues a verification job and answers right away.
+// The Location header points at the job, where the results appear once it's done.
 func (p *VerificationServ) runVerification(w http.ResponseWriter, service string) {
+	// Every spelling of an entity is stored under the same name
+	ref, err := ParseEntityRef(service)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	job, err := p.jobs.Submit(ref.String())
+	if err != nil {
+		w.Header().Set("Retry-After", "60")
+		http.Error(w, err.Error(), http.StatusServiceUnavailable)
+		return
+	}
+
+	w.Header().Set("content-type", jsonContentType)
+	w.Header().Set("Location", fmt.Sprintf("/v0/jobs/%d", job.ID))
 	w.WriteHeader(http.StatusAccepted)
+	json.NewEncoder(w).Encode(job)
+}
+
+// Verification jobs handler
+// Version 0 (/v0/jobs/<JOB ID>)
+// Report whether a job is queued, running, finished or failed,
+// with the full result once it's finished.
+func (p *VerificationServ) jobsHandler(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.Atoi(strings.TrimPrefix(r.URL.Path, "/v0/jobs/"))
+	if err != nil {
+		http.Error(w, "job IDs are numbers", http.StatusBadRequest)
+		return
+	}
+
+	job, ok := p.jobs.Get(id)
+	if !ok {
+		http.Error(w, fmt.Sprintf("No job found for %d, it may have finished long ago.", id), http.StatusNotFound)
+		return
+	}
+
+	w.Header().Set("content-type", jsonContentType)
+	json.NewEncoder(w).Encode(job)
+	slog.Info("Jobs API",
+		slog.String("Method", r.Method),
+		slog.String("Path", r.URL.Path),
+		slog.Int64("ContentLength", r.ContentLength),
+		slog.String("Remote", r.RemoteAddr),
+	)
+}
 
-	var err error
+// verify. Takes a service, launches testing, and stores the result.
+// This is what each job runs in the background.
+func (p *VerificationServ) verify(service string) (*VerifyResult, error) {
 	envVar := "BACKSTAGE"
 	url := fillEnvVar(envVar)
 
 	// if there's no EnvVar, log an error and go no further
 	if url == "ENOENT" {
 		slog.Error("Environment Variable not set", slog.String("Key", envVar), slog.String("Value", url))
-		return
+		return nil, fmt.Errorf("environment variable %s not set", envVar)
 	}
 
 	// Create a data object for the configuration.
@@ -169,25 +447,54 @@ func (p *VerificationServ) runVerification(w http.ResponseWriter, service string
 
 	// Read the SVC and get the "owner" string back
 	// We don't need a return, it updates the struct
-	_, err = ReadinessRead(svcconf)
+	_, err := ReadinessRead(svcconf)
 	if err != nil {
 		slog.Error("ReadinessRead Failed", slog.Any("Error", err))
+		return nil, err
+	}
+
+	// ReadinessDisplay expects an interface with this struct
+	// These values have been filled in by ReadinessRead() above
+	// Score is initialized to 100 each time,
+	//	then decremented on each failed test
+	//	that is handled by ReadinessDisplay.
+	// The GitHub-backed Checks look in whichever repository actually exists.
+	// GitHub failing to answer isn't a missing repository, so nothing is stored and the job fails.
+	repo, err := ResolveRepo(p.github, svcconf.Repos)
+	if err != nil {
+		return nil, fmt.Errorf("problem resolving the repository, %w", err)
+	}
+	stests := &SvcTestDB{Kind: svcconf.Kind, System: svcconf.System, Datetime: svcconf.Datetime, Owner: svcconf.Owner, Metadata: svcconf.Metadata, Repo: repo, GitHub: p.github, Score: 100}
+
+	// The lifecycle and type decide which Checks run and which are Required
+	policy := p.policies.Match(svcconf.Lifecycle, svcconf.Type)
+	stests.Policy = &policy
+	slog.Info("Policy Matched", slog.String("Service", service), slog.String("Policy", policy.Name), slog.String("Lifecycle", svcconf.Lifecycle), slog.String("Type", svcconf.Type))
+
+	// The catalog-backed Checks read the rest of the catalog, e.g.: to follow dependencies
+	if catalog, err := NewBackstageCatalog(url); err != nil {
+		slog.Error("Catalog Unavailable", slog.Any("Error", err))
 	} else {
-		// ReadinessDisplay expects an interface with this struct
-		// These values have been filled in by ReadinessRead() above
-		// Score is initialized to 100 each time,
-		//	then decremented on each failed test
-		//	that is handled by ReadinessDisplay.
-		stests := &SvcTestDB{Datetime: svcconf.Datetime, Owner: svcconf.Owner, Score: 100}
-
-		// Send test metadata to ReadinessDisplay, which launches tests and displays the results.
-		// w == http.ResponseWriter, which satisfies io.Writer
-		err = ReadinessDisplay(stests, service, w)
-		if err != nil {
-			slog.Error("ReadinessDisplay Failed", slog.Any("Error", err))
-		}
+		stests.Catalog = catalog
+	}
+
+	// Send test metadata to ReadinessDisplay, which launches tests and displays the results.
+	// Nobody is waiting on the request anymore, so the display goes to the log.
+	var display strings.Builder
+	result, err := ReadinessDisplay(stests, service, &display)
+	if err != nil {
+		slog.Error("ReadinessDisplay Failed", slog.Any("Error", err))
+	}
+	slog.Debug("Verification Result", slog.String("Service", service), slog.String("Result", display.String()))
 
-		// Initiate the TriggerID sequence that is used to set WMService.Score in the database.
-		p.store.TriggerID(service, stests.Score)
+	// A result cut short by GitHub isn't stored, the job fails and the Scheduler tries again next round.
+	if err := stests.Outage(); err != nil {
+		return nil, fmt.Errorf("problem reading GitHub, %w", err)
 	}
+
+	// Initiate the TriggerID sequence that is used to set WMService.Score in the database.
+	// This also stamps the result with its run ID.
+	p.store.TriggerID(service, result)
+
+	return result, nil
 }
@@ -8,7 +8,11 @@ import (
 	"net/http"
 	"net/http/httptest"
 	"reflect"
+	"strings"
 	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
 )
 
 // This is a data stub for mocking activities on the server
@@ -17,6 +21,7 @@ type StubServiceStore struct {
 	IDs         map[string]int
 	verifyCalls []string
 	almanac     []WMService
+	history     History
 }
 
 func (s *StubServiceStore) GetTriggerID(name string) int {
@@ -24,7 +29,7 @@ func (s *StubServiceStore) GetTriggerID(name string) int {
 	return lastID
 }
 
-func (s *StubServiceStore) TriggerID(name string, score int) {
+func (s *StubServiceStore) TriggerID(name string, result *VerifyResult) {
 	s.verifyCalls = append(s.verifyCalls, name)
 }
 
@@ -32,6 +37,10 @@ func (s *StubServiceStore) GetAlmanac() Almanac {
 	return s.almanac
 }
 
+func (s *StubServiceStore) GetHistory(name string, from, to time.Time) []VerifyResult {
+	return s.history.Between(name, from, to)
+}
+
 // Test /almanac endpoint with JSON output
 func TestAlmanac(t *testing.T) {
 
@@ -39,12 +48,12 @@ func TestAlmanac(t *testing.T) {
 		// Can the Almanac return three values?
 		// name, lastid, score
 		wantedAlmanac := []WMService{
-			{"svcA", 3, 50},
-			{"svcB", 5, 60},
-			{"svcC", 8, 70},
+			{Name: "svcA", LastID: 3, Score: 50, Principles: PrincipleScores{Reliability: 50}},
+			{Name: "svcB", LastID: 5, Score: 60},
+			{Name: "svcC", LastID: 8, Score: 70},
 		}
 
-		store := StubServiceStore{nil, nil, wantedAlmanac}
+		store := StubServiceStore{almanac: wantedAlmanac}
 		server := NewVerificationServ(&store)
 
 		request := newAlmanacRequest()
@@ -59,15 +68,72 @@ func TestAlmanac(t *testing.T) {
 	})
 }
 
+// Test /v0/history endpoint with JSON output
+func TestHistory(t *testing.T) {
+	store := StubServiceStore{
+		history: History{
+			{Service: "admin", RunID: 1, Datetime: 1000, Score: 98},
+			{Service: "core", RunID: 1, Datetime: 1500, Score: 97},
+			{Service: "admin", RunID: 2, Datetime: 2000, Score: 99},
+			{Service: "admin", RunID: 3, Datetime: 3000, Score: 100},
+		},
+	}
+	server := NewVerificationServ(&store)
+
+	historyTests := []struct {
+		Name   string
+		Query  string
+		Expect []int // RunIDs
+	}{
+		{"whole history", "", []int{1, 2, 3}},
+		{"from unix seconds", "?from=2000", []int{2, 3}},
+		{"up to unix seconds", "?to=2000", []int{1, 2}},
+		{"RFC3339 range", "?from=1970-01-01T00:20:00Z&to=1970-01-01T00:40:00Z", []int{2}},
+		{"nothing in range", "?from=5000", []int{}},
+	}
+
+	for _, tt := range historyTests {
+		t.Run(tt.Name, func(t *testing.T) {
+			request, _ := http.NewRequest(http.MethodGet, "/v0/history/admin"+tt.Query, nil)
+			response := httptest.NewRecorder()
+
+			server.ServeHTTP(response, request)
+
+			assertStatus(t, response.Code, http.StatusOK)
+			assertContentType(t, response, jsonContentType)
+
+			var runs []VerifyResult
+			if err := json.NewDecoder(response.Body).Decode(&runs); err != nil {
+				t.Fatalf("Unable to parse history from server, '%v'", err)
+			}
+			got := []int{}
+			for _, r := range runs {
+				got = append(got, r.RunID)
+			}
+			if !reflect.DeepEqual(got, tt.Expect) {
+				t.Errorf("got run IDs %v want %v", got, tt.Expect)
+			}
+		})
+	}
+
+	t.Run("bad times are rejected", func(t *testing.T) {
+		request, _ := http.NewRequest(http.MethodGet, "/v0/history/admin?from=yesterday", nil)
+		response := httptest.NewRecorder()
+
+		server.ServeHTTP(response, request)
+
+		assertStatus(t, response.Code, http.StatusBadRequest)
+	})
+}
+
 // GET endpoint
 func TestGETServices(t *testing.T) {
 	// Make a new stub "store" to use in testing
 	store := StubServiceStore{
-		map[string]int{
+		IDs: map[string]int{
 			"admin":  20,
 			"Craque": 10,
 		},
-		nil, nil,
 	}
 	// A new struct with an internal reference to the interface
 	server := NewVerificationServ(&store)
@@ -110,8 +176,7 @@ func TestGETServices(t *testing.T) {
 // healthz endpoint
 func TestHealthZ(t *testing.T) {
 	store := StubServiceStore{
-		map[string]int{},
-		nil, nil,
+		IDs: map[string]int{},
 	}
 	server := NewVerificationServ(&store)
 
@@ -129,10 +194,10 @@ func TestHealthZ(t *testing.T) {
 // POST endpoint
 func TestStoreIDs(t *testing.T) {
 	store := StubServiceStore{
-		map[string]int{},
-		nil, nil,
+		IDs: map[string]int{},
 	}
 	server := NewVerificationServ(&store)
+	offline(t, server)
 
 	t.Run("it returns accepted on POST", func(t *testing.T) {
 		service := "admin"
@@ -142,6 +207,7 @@ func TestStoreIDs(t *testing.T) {
 		server.ServeHTTP(response, request)
 
 		assertStatus(t, response.Code, http.StatusAccepted)
+		waitForJob(t, server, response)
 
 		if len(store.verifyCalls) != 1 {
 			t.Errorf("got %d calls to TriggerID want %d", len(store.verifyCalls), 1)
@@ -151,6 +217,13 @@ func TestStoreIDs(t *testing.T) {
 			t.Errorf("did not store correct service got %q want %q", store.verifyCalls[0], service)
 		}
 	})
+
+	t.Run("it rejects kinds that can't be verified", func(t *testing.T) {
+		response := httptest.NewRecorder()
+		server.ServeHTTP(response, newPostIDReq("group:code-owners-core"))
+
+		assertStatus(t, response.Code, http.StatusBadRequest)
+	})
 }
 
 // This saves us from not having to test the temporary InMemoryServiceStore
@@ -167,11 +240,14 @@ func TestRecordingIDsAndRetrievingThem(t *testing.T) {
 	}
 
 	server := NewVerificationServ(store)
+	offline(t, server)
 	service := "admin"
 
-	server.ServeHTTP(httptest.NewRecorder(), newPostIDReq(service))
-	server.ServeHTTP(httptest.NewRecorder(), newPostIDReq(service))
-	server.ServeHTTP(httptest.NewRecorder(), newPostIDReq(service))
+	for i := 0; i < 3; i++ {
+		posted := httptest.NewRecorder()
+		server.ServeHTTP(posted, newPostIDReq(service))
+		waitForJob(t, server, posted)
+	}
 
 	response := httptest.NewRecorder()
 	server.ServeHTTP(response, newGetTriggerIDReq(service))
@@ -180,6 +256,347 @@ func TestRecordingIDsAndRetrievingThem(t *testing.T) {
 	assertResponseBody(t, response.Body.String(), "LastID for "+service+": 3\n")
 }
 
+// A verification end to end: the catalog from the fake Backstage,
+// the code from the fake GitHub, through every registered Check.
+func TestVerifyEndToEnd(t *testing.T) {
+	database, cleanDatabase := createTempFile(t, `[]`)
+	defer cleanDatabase()
+
+	store, err := NewFSStore(database)
+	assertNoError(t, err)
+	server := NewVerificationServ(store)
+	offline(t, server)
+
+	verifyTests := []struct {
+		Service string
+		System  string // The System a part belongs to
+		Score   int
+		Status  Readiness
+		Policy  string   // The Policy it's verified under
+		Failed  []string // The IDs of the Checks that fail
+	}{
+		{"admin", "", 87, NotReady, "default", []string{"dockerfile", "readme", "adr", "techdocs", "branch-protection", "security-policy", "dependency-updates", "probes", "resources", "replicas", "disruption-budget", "annotations", "owner"}}, // CODEOWNERS names another team, only a runbook for docs, a single replica and a bare chart, a root image
+		{"core", "", 100, Ready, "default", nil}, // Its code is in GhostGroup/weedmaps
+		{"ad-server", "", 87, Degraded, "default", []string{"dependencies", "adr", "branch-protection", "security-policy", "license", "probes", "resources", "replicas", "disruption-budget", "description", "tags", "links", "annotations"}}, // CODEOWNERS is in docs/, a part depends on a missing Resource, the bidder Deployment is bare
+		{"weedmaps-api", "", 95, NotReady, "default", []string{"description", "tags", "links", "annotations", "owner", "repository"}},                                                                                                         // There's no repository, and nothing but an owner
+		{"component:core-braze", "core", 100, Ready, "default", nil}, // Its code is in its System's repository, fully described
+		{"resource:core-db", "core", 100, Ready, "default", nil},     // Owned by a full group reference
+		{"api:ad-server-api", "ad-server", 92, Degraded, "default", []string{"adr", "branch-protection", "security-policy", "license", "probes", "resources", "replicas", "disruption-budget"}},                                                                                // Its project-slug is the System's, which keeps no ADRs and deploys the bidder
+		{"component:ad-server-bidder", "ad-server", 100, Ready, "experimental", nil},                                                                                                                                                                                           // Experimental, only held to the basics
+		{"component:ad-server-cache-builder", "ad-server", 87, Degraded, "default", []string{"dependencies", "adr", "branch-protection", "security-policy", "license", "probes", "resources", "replicas", "disruption-budget", "description", "tags", "links", "annotations"}}, // Depends on the experimental bidder
+	}
+
+	for _, tt := range verifyTests {
+		t.Run(tt.Service, func(t *testing.T) {
+			posted := httptest.NewRecorder()
+			server.ServeHTTP(posted, newPostIDReq(tt.Service))
+			assertStatus(t, posted.Code, http.StatusAccepted)
+
+			job := waitForJob(t, server, posted)
+			if job.Status != JobFinished || job.Result == nil {
+				t.Fatalf("got job %+v want a finished result", job)
+			}
+
+			var failed []string
+			for _, tr := range job.Result.Results {
+				if !tr.Works {
+					failed = append(failed, tr.ID)
+				}
+			}
+			if job.Result.Score != tt.Score || job.Result.Status != tt.Status || !reflect.DeepEqual(failed, tt.Failed) {
+				t.Errorf("got score %d, %s, failing %v want score %d, %s, failing %v",
+					job.Result.Score, job.Result.Status, failed, tt.Score, tt.Status, tt.Failed)
+			}
+			assertResponseBody(t, job.Result.System, tt.System)
+			assertResponseBody(t, job.Result.Policy, tt.Policy)
+		})
+	}
+
+	t.Run("parts roll up into their System's view", func(t *testing.T) {
+		response := httptest.NewRecorder()
+		server.ServeHTTP(response, httptest.NewRequest(http.MethodGet, "/v0/systems/core", nil))
+		assertStatus(t, response.Code, http.StatusOK)
+
+		var view SystemView
+		if err := json.NewDecoder(response.Body).Decode(&view); err != nil {
+			t.Fatalf("Unable to parse response from server %q into SystemView, '%v'", response.Body, err)
+		}
+		if view.System.Name != "core" || len(view.Parts) != 2 {
+			t.Errorf("got %+v want core with its 2 parts", view)
+		}
+		if diff := cmp.Diff(view.Rollup, &Rollup{AggregateMin, 100, Ready, 3}); diff != "" {
+			t.Error(diff)
+		}
+	})
+
+	t.Run("every System is a tree, rolled up the way it's asked for", func(t *testing.T) {
+		response := httptest.NewRecorder()
+		server.ServeHTTP(response, httptest.NewRequest(http.MethodGet, "/v0/systems/?aggregation=weighted-mean", nil))
+		assertStatus(t, response.Code, http.StatusOK)
+
+		var views []SystemView
+		if err := json.NewDecoder(response.Body).Decode(&views); err != nil {
+			t.Fatalf("Unable to parse response from server %q into []SystemView, '%v'", response.Body, err)
+		}
+		if len(views) != 4 {
+			t.Fatalf("got %d Systems want 4", len(views))
+		}
+		for _, view := range views {
+			if view.Rollup == nil || view.Rollup.Aggregation != AggregateWeightedMean {
+				t.Errorf("got %s rolled up as %+v want weighted-mean", view.System.Name, view.Rollup)
+			}
+		}
+
+		// The parts come from the catalog, where admin's Component has never been verified
+		if diff := cmp.Diff(views[1].Unverified, []string{"component:admin"}); diff != "" {
+			t.Error(diff)
+		}
+	})
+
+	t.Run("unknown aggregations are rejected", func(t *testing.T) {
+		response := httptest.NewRecorder()
+		server.ServeHTTP(response, httptest.NewRequest(http.MethodGet, "/v0/systems/core?aggregation=median", nil))
+		assertStatus(t, response.Code, http.StatusBadRequest)
+	})
+
+	t.Run("dependencies are reported with their readiness", func(t *testing.T) {
+		response := httptest.NewRecorder()
+		server.ServeHTTP(response, httptest.NewRequest(http.MethodGet, "/v0/dependencies/component:ad-server-cache-builder", nil))
+		assertStatus(t, response.Code, http.StatusOK)
+		assertContentType(t, response, jsonContentType)
+
+		var report DependencyReport
+		if err := json.NewDecoder(response.Body).Decode(&report); err != nil {
+			t.Fatalf("Unable to parse response from server %q into DependencyReport, '%v'", response.Body, err)
+		}
+		want := DependencyReport{
+			Service: "component:ad-server-cache-builder",
+			Dependencies: []UpstreamStatus{
+				{Dependency: Dependency{Name: "component:ad-server-bidder", Field: "spec.dependsOn"}, InCatalog: true, Lifecycle: LifecycleExperimental, Verified: true, Score: 100, Status: Ready},
+				{Dependency: Dependency{Name: "resource:ad-server-redis", Field: "spec.dependsOn"}},
+			},
+			BelowBaseline: []string{},
+		}
+		if diff := cmp.Diff(report, want); diff != "" {
+			t.Error(diff)
+		}
+	})
+
+	t.Run("dependencies of services outside the catalog are not found", func(t *testing.T) {
+		response := httptest.NewRecorder()
+		server.ServeHTTP(response, httptest.NewRequest(http.MethodGet, "/v0/dependencies/component:nobody", nil))
+		assertStatus(t, response.Code, http.StatusNotFound)
+	})
+}
+
+// A GitHub outage fails the job instead of storing a verdict about the Service
+func TestVerifyGitHubOutage(t *testing.T) {
+	database, cleanDatabase := createTempFile(t, `[]`)
+	defer cleanDatabase()
+
+	store, err := NewFSStore(database)
+	assertNoError(t, err)
+	server := NewVerificationServ(store)
+	offline(t, server)
+	server.github, _, _ = newTestGitHubClient(t, answer(http.StatusServiceUnavailable, ""))
+
+	posted := httptest.NewRecorder()
+	server.ServeHTTP(posted, newPostIDReq("admin"))
+	assertStatus(t, posted.Code, http.StatusAccepted)
+
+	job := waitForJob(t, server, posted)
+	if job.Status != JobFailed || job.Result != nil {
+		t.Fatalf("got job %+v want it failed without a result", job)
+	}
+	assertIDEquals(t, store.GetTriggerID("admin"), 0)
+}
+
+// Verification jobs endpoint
+func TestJobs(t *testing.T) {
+	store := StubServiceStore{
+		IDs: map[string]int{},
+	}
+	server := NewVerificationServ(&store)
+
+	// Stand in for Backstage and GitHub, "broken" fails to verify
+	release := make(chan struct{})
+	server.jobs = NewJobQueue(1, 1, func(service string) (*VerifyResult, error) {
+		<-release
+		if service == "broken" {
+			return nil, fmt.Errorf("no catalog entry for %s", service)
+		}
+		return &VerifyResult{Service: service, RunID: 1, Score: 99, Status: Degraded}, nil
+	})
+
+	t.Run("POST queues a job and points at it", func(t *testing.T) {
+		response := httptest.NewRecorder()
+		server.ServeHTTP(response, newPostIDReq("admin"))
+
+		assertStatus(t, response.Code, http.StatusAccepted)
+		assertContentType(t, response, jsonContentType)
+
+		location := response.Header().Get("Location")
+		job := getJobFromResponse(t, response.Body)
+		if location != fmt.Sprintf("/v0/jobs/%d", job.ID) {
+			t.Errorf("got Location %q for job %d", location, job.ID)
+		}
+		if job.Service != "admin" || job.Status != JobQueued {
+			t.Errorf("got job %+v want a queued admin job", job)
+		}
+
+		release <- struct{}{}
+		got := waitForJob(t, server, response)
+		if got.Status != JobFinished || got.Result == nil || got.Result.Score != 99 {
+			t.Errorf("got job %+v want it finished with a Score of 99", got)
+		}
+	})
+
+	t.Run("failed verifications are reported on the job", func(t *testing.T) {
+		response := httptest.NewRecorder()
+		server.ServeHTTP(response, newPostIDReq("broken"))
+
+		release <- struct{}{}
+		got := waitForJob(t, server, response)
+		if got.Status != JobFailed || got.Error != "no catalog entry for broken" {
+			t.Errorf("got job %+v want it failed", got)
+		}
+	})
+
+	t.Run("a full queue turns requests away", func(t *testing.T) {
+		// One job is held by the worker and one fills the queue
+		held := httptest.NewRecorder()
+		server.ServeHTTP(held, newPostIDReq("admin"))
+		waitForStatus(t, server, held, JobRunning)
+		queued := httptest.NewRecorder()
+		server.ServeHTTP(queued, newPostIDReq("admin"))
+
+		response := httptest.NewRecorder()
+		server.ServeHTTP(response, newPostIDReq("admin"))
+
+		assertStatus(t, response.Code, http.StatusServiceUnavailable)
+		if response.Header().Get("Retry-After") == "" {
+			t.Error("expected a Retry-After header")
+		}
+
+		release <- struct{}{}
+		release <- struct{}{}
+		waitForJob(t, server, queued)
+	})
+
+	t.Run("unknown jobs are not found", func(t *testing.T) {
+		request, _ := http.NewRequest(http.MethodGet, "/v0/jobs/9999", nil)
+		response := httptest.NewRecorder()
+
+		server.ServeHTTP(response, request)
+
+		assertStatus(t, response.Code, http.StatusNotFound)
+	})
+
+	t.Run("job IDs are numbers", func(t *testing.T) {
+		request, _ := http.NewRequest(http.MethodGet, "/v0/jobs/admin", nil)
+		response := httptest.NewRecorder()
+
+		server.ServeHTTP(response, request)
+
+		assertStatus(t, response.Code, http.StatusBadRequest)
+	})
+}
+
+// Scheduler admin endpoint
+func TestSchedulerAdmin(t *testing.T) {
+	store := StubServiceStore{
+		IDs: map[string]int{},
+	}
+	server := NewVerificationServ(&store)
+
+	t.Run("returns 404 when the scheduler is off", func(t *testing.T) {
+		request, _ := http.NewRequest(http.MethodGet, "/admin/scheduler", nil)
+		response := httptest.NewRecorder()
+
+		server.ServeHTTP(response, request)
+
+		assertStatus(t, response.Code, http.StatusNotFound)
+		if !strings.Contains(response.Body.String(), "SCHEDULE_INTERVAL is 0") {
+			t.Errorf("got %q want it to say what turned the scheduler off", response.Body.String())
+		}
+	})
+
+	t.Run("returns the scheduler state as JSON", func(t *testing.T) {
+		discover := func() ([]string, error) { return nil, fmt.Errorf("environment variable BACKSTAGE not set") }
+		server.scheduler = NewScheduler(ScheduleConfig{Interval: 6 * time.Hour, Concurrency: 2}, discover, server.jobs)
+		server.scheduler.round()
+
+		request, _ := http.NewRequest(http.MethodGet, "/admin/scheduler", nil)
+		response := httptest.NewRecorder()
+
+		server.ServeHTTP(response, request)
+
+		assertStatus(t, response.Code, http.StatusOK)
+		assertContentType(t, response, jsonContentType)
+
+		var got SchedulerState
+		if err := json.NewDecoder(response.Body).Decode(&got); err != nil {
+			t.Fatalf("Unable to parse scheduler state from server, '%v'", err)
+		}
+		if got.Interval != "6h0m0s" || got.Rounds != 1 || got.LastError != "environment variable BACKSTAGE not set" {
+			t.Errorf("got state %+v", got)
+		}
+	})
+}
+
+// waitForJob follows the Location of a queued job until it's done,
+// and returns the job as the /v0/jobs/ endpoint shows it.
+func waitForJob(t testing.TB, server *VerificationServ, posted *httptest.ResponseRecorder) Job {
+	t.Helper()
+
+	var id int
+	if _, err := fmt.Sscanf(posted.Header().Get("Location"), "/v0/jobs/%d", &id); err != nil {
+		t.Fatalf("no job Location in response %q, '%v'", posted.Body, err)
+	}
+	if !server.jobs.Wait(id, time.Minute) {
+		t.Fatalf("job %d did not finish", id)
+	}
+
+	return getJob(t, server, id)
+}
+
+// waitForStatus polls a queued job until it reaches /status/.
+func waitForStatus(t testing.TB, server *VerificationServ, posted *httptest.ResponseRecorder, status JobStatus) {
+	t.Helper()
+
+	var id int
+	fmt.Sscanf(posted.Header().Get("Location"), "/v0/jobs/%d", &id)
+	for i := 0; i < 1000; i++ {
+		if getJob(t, server, id).Status == status {
+			return
+		}
+		time.Sleep(time.Millisecond)
+	}
+	t.Fatalf("job %d never became %s", id, status)
+}
+
+func getJob(t testing.TB, server *VerificationServ, id int) Job {
+	t.Helper()
+
+	request, _ := http.NewRequest(http.MethodGet, fmt.Sprintf("/v0/jobs/%d", id), nil)
+	response := httptest.NewRecorder()
+	server.ServeHTTP(response, request)
+	assertStatus(t, response.Code, http.StatusOK)
+
+	return getJobFromResponse(t, response.Body)
+}
+
+func getJobFromResponse(t testing.TB, body io.Reader) (job Job) {
+	t.Helper()
+	err := json.NewDecoder(body).Decode(&job)
+
+	if err != nil {
+		t.Fatalf("Unable to parse response from server %q into Job, '%v'", body, err)
+	}
+
+	return
+}
+
 func newGetTriggerIDReq(name string) *http.Request {
 	req, _ := http.NewRequest(http.MethodGet, fmt.Sprintf("/v0/%s", name), nil)
 	return req
@@ -238,3 +655,43 @@ func assertContentType(t testing.TB, response *httptest.ResponseRecorder, want s
 		t.Errorf("response did not have content-type of %s, got %v", want, response.Result().Header)
 	}
 }
+
+// offline points the server at the fake Backstage and the fake GitHub,
+// so a verification runs without the network.
+func offline(t *testing.T, server *VerificationServ) {
+	t.Helper()
+	t.Setenv("BACKSTAGE", newFakeBackstage(t, fakeBackstageFixtures))
+	server.github, _ = newFakeGitHub(t, fakeGitHubFixtures)
+}
+
+// The System views read the catalog once in a while, not on every request
+func TestCatalogCache(t *testing.T) {
+	now := time.Now()
+	reads := 0
+	graph := &DependencyGraph{}
+	c := &catalogCache{
+		read: func() *DependencyGraph {
+			reads++
+			if reads == 3 {
+				return nil // The catalog is unavailable
+			}
+			return graph
+		},
+		now: func() time.Time { return now },
+	}
+
+	c.Graph()
+	if got := c.Graph(); got != graph || reads != 1 {
+		t.Errorf("got %p after %d reads want %p after 1", got, reads, graph)
+	}
+
+	now = now.Add(catalogGraphTTL)
+	c.Graph()
+	assertIDEquals(t, reads, 2)
+
+	// A failed read keeps the last graph
+	now = now.Add(catalogGraphTTL)
+	if got := c.Graph(); got != graph || reads != 3 {
+		t.Errorf("got %p after %d reads want %p after 3", got, reads, graph)
+	}
+}
@@ -0,0 +1,332 @@
+package main
+
+import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"time"
+
+	_ "modernc.org/sqlite" // Pure-Go SQLite driver, registers itself as "sqlite"
+)
+
+// sqlMigrations build the schema one step at a time.
+// Each entry is applied once, in order, and recorded in schema_migrations.
+// Never edit an entry that has shipped, add a new one to the end instead.
+var sqlMigrations = []string{
+	// 1: the latest view of every service, the same shape as the Almanac
+	`CREATE TABLE services (
+		name       TEXT PRIMARY KEY,
+		last_id    INTEGER NOT NULL,
+		score      INTEGER NOT NULL,
+		status     TEXT NOT NULL DEFAULT '',
+		principles TEXT NOT NULL DEFAULT '{}'
+	)`,
+	// 2: every verification run, the History
+	`CREATE TABLE runs (
+		service    TEXT NOT NULL,
+		run_id     INTEGER NOT NULL,
+		datetime   INTEGER NOT NULL,
+		score      INTEGER NOT NULL,
+		status     TEXT NOT NULL DEFAULT '',
+		principles TEXT NOT NULL DEFAULT '{}',
+		PRIMARY KEY (service, run_id)
+	);
+	CREATE INDEX runs_service_datetime ON runs (service, datetime)`,
+	// 3: one row per Check in a run, the full TestReturn is kept in detail
+	`CREATE TABLE check_results (
+		service  TEXT NOT NULL,
+		run_id   INTEGER NOT NULL,
+		seq      INTEGER NOT NULL,
+		check_id TEXT NOT NULL,
+		works    INTEGER NOT NULL,
+		penalty  INTEGER NOT NULL,
+		required INTEGER NOT NULL,
+		detail   TEXT NOT NULL,
+		PRIMARY KEY (service, run_id, seq),
+		FOREIGN KEY (service, run_id) REFERENCES runs (service, run_id)
+	)`,
+	// 4: Components, Resources and APIs are verified too, and belong to a System
+	`ALTER TABLE services ADD COLUMN kind TEXT NOT NULL DEFAULT '';
+	ALTER TABLE services ADD COLUMN system TEXT NOT NULL DEFAULT '';
+	ALTER TABLE runs ADD COLUMN kind TEXT NOT NULL DEFAULT '';
+	ALTER TABLE runs ADD COLUMN system TEXT NOT NULL DEFAULT ''`,
+	// 5: the Policy each run was verified under
+	`ALTER TABLE runs ADD COLUMN policy TEXT NOT NULL DEFAULT ''`,
+}
+
+// SQLStore keeps the Almanac and its History in an embedded SQLite database.
+// Unlike FSStore, each run only writes its own rows instead of the whole file.
+type SQLStore struct {
+	db *sql.DB
+}
+
+// NewSQLStore Constructor
+func NewSQLStore(db *sql.DB) (*SQLStore, error) {
+	// SQLite allows a single writer,
+	// so one connection keeps concurrent requests from tripping over the lock.
+	db.SetMaxOpenConns(1)
+
+	if err := migrateSQL(db); err != nil {
+		return nil, fmt.Errorf("problem migrating service db, %v", err)
+	}
+
+	return &SQLStore{db: db}, nil
+}
+
+// migrateSQL applies every migration newer than the recorded schema version.
+func migrateSQL(db *sql.DB) error {
+	if _, err := db.Exec(`CREATE TABLE IF NOT EXISTS schema_migrations (version INTEGER NOT NULL)`); err != nil {
+		return err
+	}
+
+	var version int
+	if err := db.QueryRow(`SELECT COALESCE(MAX(version), 0) FROM schema_migrations`).Scan(&version); err != nil {
+		return err
+	}
+
+	for i := version; i < len(sqlMigrations); i++ {
+		tx, err := db.Begin()
+		if err != nil {
+			return err
+		}
+		if _, err := tx.Exec(sqlMigrations[i]); err != nil {
+			tx.Rollback()
+			return fmt.Errorf("migration %d: %v", i+1, err)
+		}
+		if _, err := tx.Exec(`INSERT INTO schema_migrations (version) VALUES (?)`, i+1); err != nil {
+			tx.Rollback()
+			return fmt.Errorf("migration %d: %v", i+1, err)
+		}
+		if err := tx.Commit(); err != nil {
+			return err
+		}
+		slog.Info("Schema Migrated", slog.Int("Version", i+1))
+	}
+
+	return nil
+}
+
+// GetAlmanac provides a sorted list of all services and their LastID.
+func (s *SQLStore) GetAlmanac() Almanac {
+	rows, err := s.db.Query(`SELECT name, kind, system, last_id, score, status, principles FROM services ORDER BY last_id DESC`)
+	if err != nil {
+		slog.Error("Failed to read Almanac", slog.Any("Error", err))
+		return nil
+	}
+	defer rows.Close()
+
+	var almanac Almanac
+	for rows.Next() {
+		var service WMService
+		var principles string
+		if err := rows.Scan(&service.Name, &service.Kind, &service.System, &service.LastID, &service.Score, &service.Status, &principles); err != nil {
+			slog.Error("Failed to read Almanac", slog.Any("Error", err))
+			return almanac
+		}
+		service.Principles = decodePrinciples(principles)
+		almanac = append(almanac, service)
+	}
+	return almanac
+}
+
+// GetTriggerID does a lookup for the LastID for a given name.
+func (s *SQLStore) GetTriggerID(name string) int {
+	var lastID int
+	err := s.db.QueryRow(`SELECT last_id FROM services WHERE name = ?`, name).Scan(&lastID)
+	if err != nil && err != sql.ErrNoRows {
+		slog.Error("Failed to read LastID", slog.String("Service", name), slog.Any("Error", err))
+	}
+	return lastID
+}
+
+// GetScore is a lookup for the Score for a given name.
+func (s *SQLStore) GetScore(name string) int {
+	var score int
+	err := s.db.QueryRow(`SELECT score FROM services WHERE name = ?`, name).Scan(&score)
+	if err != nil && err != sql.ErrNoRows {
+		slog.Error("Failed to read Score", slog.String("Service", name), slog.Any("Error", err))
+	}
+	return score
+}
+
+// TriggerID increases LastID by one, providing a run count,
+// and records the run with its per-check results.
+// If it's a new service, create them and start their tally at 1.
+func (s *SQLStore) TriggerID(name string, result *VerifyResult) {
+	tx, err := s.db.Begin()
+	if err != nil {
+		slog.Error("Failed to store run", slog.String("Service", name), slog.Any("Error", err))
+		return
+	}
+
+	var lastID int
+	err = tx.QueryRow(`SELECT last_id FROM services WHERE name = ?`, name).Scan(&lastID)
+	if err != nil && err != sql.ErrNoRows {
+		tx.Rollback()
+		slog.Error("Failed to store run", slog.String("Service", name), slog.Any("Error", err))
+		return
+	}
+
+	record := newRunRecord(name, lastID+1, result)
+	result.RunID = record.RunID
+	err = upsertService(tx, record)
+	if err == nil {
+		err = insertRun(tx, record)
+	}
+	if err != nil {
+		tx.Rollback()
+		slog.Error("Failed to store run", slog.String("Service", name), slog.Any("Error", err))
+		return
+	}
+
+	if err := tx.Commit(); err != nil {
+		slog.Error("Failed to store run", slog.String("Service", name), slog.Any("Error", err))
+	}
+}
+
+// GetHistory returns every stored run for a service inside a time range.
+// A zero /from/ or /to/ leaves that end of the range open.
+func (s *SQLStore) GetHistory(name string, from, to time.Time) []VerifyResult {
+	query := `SELECT kind, system, policy, run_id, datetime, score, status, principles FROM runs WHERE service = ?`
+	args := []any{name}
+	if !from.IsZero() {
+		query += ` AND datetime >= ?`
+		args = append(args, from.Unix())
+	}
+	if !to.IsZero() {
+		query += ` AND datetime <= ?`
+		args = append(args, to.Unix())
+	}
+	query += ` ORDER BY run_id`
+
+	rows, err := s.db.Query(query, args...)
+	if err != nil {
+		slog.Error("Failed to read History", slog.String("Service", name), slog.Any("Error", err))
+		return nil
+	}
+
+	var runs []VerifyResult
+	for rows.Next() {
+		run := VerifyResult{Service: name}
+		var principles string
+		if err := rows.Scan(&run.Kind, &run.System, &run.Policy, &run.RunID, &run.Datetime, &run.Score, &run.Status, &principles); err != nil {
+			slog.Error("Failed to read History", slog.String("Service", name), slog.Any("Error", err))
+			break
+		}
+		run.Principles = decodePrinciples(principles)
+		runs = append(runs, run)
+	}
+	rows.Close()
+
+	// Fill in each run's per-check results once the run rows are closed,
+	// there's only the one connection to go around.
+	for i := range runs {
+		runs[i].Results = s.checkResults(name, runs[i].RunID)
+	}
+	return runs
+}
+
+// Import loads an Almanac and its History, e.g. from an FSStore database, into this store.
+// Services already in this store are overwritten.
+func (s *SQLStore) Import(almanac Almanac, history History) error {
+	tx, err := s.db.Begin()
+	if err != nil {
+		return err
+	}
+
+	for _, service := range almanac {
+		latest := VerifyResult{Service: service.Name, Kind: service.Kind, System: service.System, RunID: service.LastID, Score: service.Score, Status: service.Status, Principles: service.Principles}
+		if err := upsertService(tx, latest); err != nil {
+			tx.Rollback()
+			return fmt.Errorf("problem importing %s, %v", service.Name, err)
+		}
+	}
+	for _, run := range history {
+		if err := insertRun(tx, run); err != nil {
+			tx.Rollback()
+			return fmt.Errorf("problem importing run %d of %s, %v", run.RunID, run.Service, err)
+		}
+	}
+
+	return tx.Commit()
+}
+
+// checkResults reads back the per-check results of a single run.
+func (s *SQLStore) checkResults(name string, runID int) []*TestReturn {
+	rows, err := s.db.Query(`SELECT detail FROM check_results WHERE service = ? AND run_id = ? ORDER BY seq`, name, runID)
+	if err != nil {
+		slog.Error("Failed to read Check results", slog.String("Service", name), slog.Int("RunID", runID), slog.Any("Error", err))
+		return nil
+	}
+	defer rows.Close()
+
+	var results []*TestReturn
+	for rows.Next() {
+		var detail string
+		if err := rows.Scan(&detail); err != nil {
+			slog.Error("Failed to read Check results", slog.String("Service", name), slog.Int("RunID", runID), slog.Any("Error", err))
+			return results
+		}
+		tr := new(TestReturn)
+		if err := json.Unmarshal([]byte(detail), tr); err != nil {
+			slog.Error("Failed to parse Check result", slog.String("Service", name), slog.Int("RunID", runID), slog.Any("Error", err))
+			continue
+		}
+		results = append(results, tr)
+	}
+	return results
+}
+
+// upsertService sets the latest view of a service from a stored run.
+func upsertService(tx *sql.Tx, run VerifyResult) error {
+	principles, err := json.Marshal(run.Principles)
+	if err != nil {
+		return err
+	}
+	_, err = tx.Exec(`INSERT INTO services (name, kind, system, last_id, score, status, principles) VALUES (?, ?, ?, ?, ?, ?, ?)
+		ON CONFLICT (name) DO UPDATE SET kind = excluded.kind, system = excluded.system, last_id = excluded.last_id,
+			score = excluded.score, status = excluded.status, principles = excluded.principles`,
+		run.Service, run.Kind, run.System, run.RunID, run.Score, run.Status, string(principles))
+	return err
+}
+
+// insertRun adds a run and its per-check results to the History.
+func insertRun(tx *sql.Tx, run VerifyResult) error {
+	principles, err := json.Marshal(run.Principles)
+	if err != nil {
+		return err
+	}
+	_, err = tx.Exec(`INSERT INTO runs (service, kind, system, policy, run_id, datetime, score, status, principles) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?)`,
+		run.Service, run.Kind, run.System, run.Policy, run.RunID, run.Datetime, run.Score, run.Status, string(principles))
+	if err != nil {
+		return err
+	}
+
+	for seq, tr := range run.Results {
+		detail, err := json.Marshal(tr)
+		if err != nil {
+			return err
+		}
+		_, err = tx.Exec(`INSERT INTO check_results (service, run_id, seq, check_id, works, penalty, required, detail) VALUES (?, ?, ?, ?, ?, ?, ?, ?)`,
+			run.Service, run.RunID, seq, tr.ID, tr.Works, tr.Penalty, tr.Required, string(detail))
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// decodePrinciples reads a stored PrincipleScores column,
+// an empty breakdown comes back as nil to match the JSON store.
+func decodePrinciples(raw string) PrincipleScores {
+	var principles PrincipleScores
+	if err := json.Unmarshal([]byte(raw), &principles); err != nil {
+		slog.Error("Failed to parse Principles", slog.Any("Error", err))
+		return nil
+	}
+	if len(principles) == 0 {
+		return nil
+	}
+	return principles
+}
@@ -0,0 +1,93 @@
+package main
+
+import (
+	"database/sql"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestSQLStore(t *testing.T) {
+	testServiceStore(t, newTestSQLStore)
+
+	t.Run("migrations only run once", func(t *testing.T) {
+		db := openTestSQL(t, filepath.Join(t.TempDir(), "almanac.db.sqlite"))
+
+		_, err := NewSQLStore(db)
+		assertNoError(t, err)
+		_, err = NewSQLStore(db)
+		assertNoError(t, err)
+
+		var version int
+		err = db.QueryRow(`SELECT MAX(version) FROM schema_migrations`).Scan(&version)
+		assertNoError(t, err)
+		assertIDEquals(t, version, len(sqlMigrations))
+	})
+
+	t.Run("per-check results are stored as rows", func(t *testing.T) {
+		store, _ := newTestSQLStore(t, `[]`)
+
+		store.TriggerID("admin", &VerifyResult{Datetime: 1000, Score: 99, Results: []*TestReturn{
+			{ID: "owner", Works: false, Penalty: 1, Required: true},
+			{ID: "readme", Works: true},
+		}})
+
+		var failed int
+		err := store.(*SQLStore).db.QueryRow(`SELECT COUNT(*) FROM check_results WHERE service = 'admin' AND works = 0 AND required = 1`).Scan(&failed)
+		assertNoError(t, err)
+		assertIDEquals(t, failed, 1)
+	})
+
+	t.Run("import a JSON database with its History", func(t *testing.T) {
+		store, _ := newTestSQLStore(t, `[]`)
+
+		almanac, history, err := NewDatabase(strings.NewReader(`{
+			"Almanac": [{"Name": "admin", "LastID": 2, "Score": 99, "Status": "Degraded"}],
+			"History": [
+				{"Service": "admin", "RunID": 1, "Datetime": 1000, "Score": 98},
+				{"Service": "admin", "RunID": 2, "Datetime": 2000, "Score": 99, "Status": "Degraded"}]}`))
+		assertNoError(t, err)
+		assertNoError(t, store.(*SQLStore).Import(almanac, history))
+
+		assertIDEquals(t, store.GetTriggerID("admin"), 2)
+		assertIDEquals(t, len(store.GetHistory("admin", time.Time{}, time.Time{})), 2)
+
+		// New runs carry on from the imported LastID
+		store.TriggerID("admin", &VerifyResult{Score: 100})
+		assertIDEquals(t, store.GetTriggerID("admin"), 3)
+	})
+}
+
+// newTestSQLStore seeds a temp SQLite database from a JSON almanac.
+func newTestSQLStore(t *testing.T, seed string) (suiteStore, func() suiteStore) {
+	t.Helper()
+
+	path := filepath.Join(t.TempDir(), "almanac.db.sqlite")
+	store, err := NewSQLStore(openTestSQL(t, path))
+	assertNoError(t, err)
+
+	almanac, history, err := NewDatabase(strings.NewReader(seed))
+	assertNoError(t, err)
+	assertNoError(t, store.Import(almanac, history))
+
+	reopen := func() suiteStore {
+		reloaded, err := NewSQLStore(openTestSQL(t, path))
+		assertNoError(t, err)
+		return reloaded
+	}
+
+	return store, reopen
+}
+
+func openTestSQL(t *testing.T, path string) *sql.DB {
+	t.Helper()
+
+	db, err := sql.Open("sqlite", path)
+	if err != nil {
+		t.Fatalf("could not open sqlite database %v", err)
+	}
+	t.Cleanup(func() { db.Close() })
+
+	return db
+}
@@ -67,15 +67,34 @@ func hashBarSVG(c int, sd *WMService, sc *SVGCfg) string {
 	// H == Y coordinate for drawing the box (right side)
 	// z == X coordinate for completing the box (bottom)
 	//
+	// The Readiness status sits just above the LastID in a smaller font
+	//
 	h := sc.Gutter + (c * sc.Spacer)             // Y coordinate for drawing the box (top)
 	y := sc.Gutter + sc.TxtOff + (c * sc.Spacer) //  Y coordinate for drawing the text line
 	return fmt.Sprintf(`<path d="M5 %dh100v5H5z" fill="tomato" />
     <path d="M5 %dh%dv10H5z" fill="darkgreen" />
     <text x="10" y="%d" font-family="Helvetica" font-size="14" font-weight="bolder" font-style="oblique" fill="#f08080" fill-opacity=".5" stroke-width=".5" stro-kopacity=".5" stroke-linejoin="round" stroke="coral">%d</text>
 	<text x="30" y="%d" font-family="Palatino" font-style="oblique" font-size="6" fill="snow" fill-opacity="1" >%d</text>
+	<text x="30" y="%d" font-family="Palatino" font-size="4" fill="%s" fill-opacity="1" >%s</text>
     <text x="40" y="%d" font-family="Monaco" font-size="12" fill="turquoise" fill-opacity="1" stroke-width=".5" stroke-linejoin="miter" stroke="darkmagenta">%s</text>`,
 		h, h, sd.Score,
 		y, sd.Score,
 		y, sd.LastID,
+		y-6, statusFill(sd.Status), sd.Status,
 		y, sd.Name)
 }
+
+// statusFill picks the text color for a Readiness status.
+// Services stored before Readiness existed have no status and stay neutral.
+func statusFill(r Readiness) string {
+	switch r {
+	case Ready:
+		return "lime"
+	case Degraded:
+		return "gold"
+	case NotReady:
+		return "tomato"
+	default:
+		return "snow"
+	}
+}
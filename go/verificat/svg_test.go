@@ -8,8 +8,8 @@ import (
 func TestBuildSVG(t *testing.T) {
 	// Create a test database for drawing SVGs
 	database, cleanDatabase := createTempFile(t, `[
-			{"Name": "Mattic", "LastID": 10, "Score": 99},
-			{"Name": "Craque", "LastID": 4, "Score": 98}]`)
+			{"Name": "Mattic", "LastID": 10, "Score": 99, "Status": "Degraded"},
+			{"Name": "Craque", "LastID": 4, "Score": 98, "Status": "NotReady"}]`)
 	defer cleanDatabase()
 	store, err := NewFSStore(database)
 	assertNoError(t, err)
@@ -31,6 +31,8 @@ func TestBuildSVG(t *testing.T) {
 		"M5 31h98v10H5z",
 		"y=\"25\"",
 		"y=\"39\"",
+		`y="19" font-family="Palatino" font-size="4" fill="gold" fill-opacity="1" >Degraded`,
+		`y="33" font-family="Palatino" font-size="4" fill="tomato" fill-opacity="1" >NotReady`,
 	}
 
 	for _, want := range wants {
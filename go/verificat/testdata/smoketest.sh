@@ -27,9 +27,18 @@ almsize=$(printf %s "$almanac" | wc -c)
 print "$almsize bytes"
 
 print -n "Admin service check... "
-adminalive=$(curl -s -X POST $VCAT/v0/admin)
-if [[ -n $adminalive ]]; then
-  print $adminalive | jq
+adminjob=$(curl -s -o /dev/null -w '%header{location}' -X POST $VCAT/v0/admin)
+if [[ -n $adminjob ]]; then
+  print -n "queued $adminjob... "
+  # Poll the job until the verification is done
+  jobstatus=queued
+  while [[ $jobstatus == queued || $jobstatus == running ]]; do
+    sleep 1
+    adminalive=$(curl -s $VCAT$adminjob)
+    jobstatus=$(print $adminalive | jq -r .Status)
+  done
+  print $jobstatus
+  print $adminalive | jq .Result
 else
   print $?
   print "Sorry, no admin data was found. Have you set BACKSTAGE and GH_TOKEN?\n"
@@ -1,8 +1,9 @@
 package main
 
 import (
-	"io"
 	"os"
+	"path/filepath"
+	"strings"
 )
 
 // Return the value of a runtime Environment Variable
@@ -15,15 +16,71 @@ func fillEnvVar(ev string) string {
 	return value
 }
 
-// When we write, we write from the beginning.
-// This type takes a file and makes sure we're at the start.
-type tape struct {
-	file *os.File
+// envList reads a comma-separated Environment Variable, e.g.: "docs/runbook.md, RUNBOOK.md".
+// Unset, it's /defaults/.
+func envList(ev string, defaults []string) []string {
+	value := fillEnvVar(ev)
+	if value == "ENOENT" {
+		return defaults
+	}
+
+	var list []string
+	for _, item := range strings.Split(value, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			list = append(list, item)
+		}
+	}
+	return list
+}
+
+// When we write, we replace the whole file in one step.
+// This type takes a file path and makes sure a reader only ever sees
+// the old contents or the new contents, never a half-written file.
+type atomicFile struct {
+	path string
 }
 
-// Return the file start location of 0 for writing.
-func (t *tape) Write(p []byte) (n int, err error) {
-	t.file.Truncate(0)
-	t.file.Seek(0, io.SeekStart)
-	return t.file.Write(p)
+// Write the new contents to a temp file next to the target,
+// flush it to disk, then rename it over the target.
+// A crash before the rename leaves the old file untouched.
+func (a *atomicFile) Write(p []byte) (n int, err error) {
+	dir := filepath.Dir(a.path)
+
+	// Keep the mode of the file being replaced
+	mode := os.FileMode(0666)
+	if info, err := os.Stat(a.path); err == nil {
+		mode = info.Mode().Perm()
+	}
+
+	tmp, err := os.CreateTemp(dir, "."+filepath.Base(a.path)+".tmp-*")
+	if err != nil {
+		return 0, err
+	}
+	// Once the rename is done this has nothing left to remove
+	defer os.Remove(tmp.Name())
+
+	if n, err = tmp.Write(p); err != nil {
+		tmp.Close()
+		return n, err
+	}
+	if err = tmp.Sync(); err != nil {
+		tmp.Close()
+		return n, err
+	}
+	if err = tmp.Close(); err != nil {
+		return n, err
+	}
+	if err = os.Chmod(tmp.Name(), mode); err != nil {
+		return n, err
+	}
+	if err = os.Rename(tmp.Name(), a.path); err != nil {
+		return n, err
+	}
+
+	// Flush the directory entry so the rename itself survives a crash
+	if d, err := os.Open(dir); err == nil {
+		d.Sync()
+		d.Close()
+	}
+	return n, nil
 }
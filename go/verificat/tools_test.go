@@ -1,8 +1,9 @@
 package main
 
 import (
-	"io"
 	"os"
+	"path/filepath"
+	"slices"
 	"testing"
 )
 
@@ -35,17 +36,36 @@ func TestFillEnvVar(t *testing.T) {
 	})
 }
 
-// Test that we can set a file to 0 for writing
-func TestTape_Write(t *testing.T) {
+// A list Environment Variable is split on commas, or falls back to its defaults
+func TestEnvList(t *testing.T) {
+	defaults := []string{"docs/runbook.md"}
+
+	t.Run("returns the defaults", func(t *testing.T) {
+		got := envList("ANYTHING", defaults)
+		if !slices.Equal(got, defaults) {
+			t.Errorf("got %v want %v", got, defaults)
+		}
+	})
+
+	t.Run("returns a set list", func(t *testing.T) {
+		t.Setenv("RUNBOOKS", " ops/runbook.md,,RUNBOOK.md ")
+		got := envList("RUNBOOKS", defaults)
+		if want := []string{"ops/runbook.md", "RUNBOOK.md"}; !slices.Equal(got, want) {
+			t.Errorf("got %v want %v", got, want)
+		}
+	})
+}
+
+// Test that a write replaces the whole file
+func TestAtomicFile_Write(t *testing.T) {
 	file, clean := createTempFile(t, "12345")
 	defer clean()
 
-	tape := &tape{file}
+	atomic := &atomicFile{file.Name()}
 
-	tape.Write([]byte("abc"))
+	atomic.Write([]byte("abc"))
 
-	file.Seek(0, io.SeekStart)
-	newFileContents, _ := io.ReadAll(file)
+	newFileContents, _ := os.ReadFile(file.Name())
 
 	got := string(newFileContents)
 	want := "abc"
@@ -53,4 +73,47 @@ func TestTape_Write(t *testing.T) {
 	if got != want {
 		t.Errorf("got %q want %q", got, want)
 	}
+
+	// Nothing is left behind next to the file
+	leftovers, _ := filepath.Glob(filepath.Join(filepath.Dir(file.Name()), "."+filepath.Base(file.Name())+".tmp-*"))
+	if len(leftovers) != 0 {
+		t.Errorf("temp files were left behind: %v", leftovers)
+	}
+}
+
+// Test that a failed write leaves the old contents in place
+func TestAtomicFile_WriteFails(t *testing.T) {
+	if os.Geteuid() == 0 {
+		t.Skip("root can write into a read-only directory")
+	}
+	dir := t.TempDir()
+	path := filepath.Join(dir, "db")
+	if err := os.WriteFile(path, []byte("12345"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	// Nothing can be created or renamed in a read-only directory
+	if err := os.Chmod(dir, 0o555); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { os.Chmod(dir, 0o755) })
+
+	atomic := &atomicFile{path}
+	if _, err := atomic.Write([]byte("abc")); err == nil {
+		t.Errorf("expected an error writing into a read-only directory")
+	}
+
+	got, _ := os.ReadFile(path)
+	if string(got) != "12345" {
+		t.Errorf("got %q want the old contents %q", got, "12345")
+	}
+}
+
+// Test that a file in a directory that doesn't exist can't be written
+func TestAtomicFile_WriteMissingDir(t *testing.T) {
+	atomic := &atomicFile{filepath.Join(t.TempDir(), "missing", "db")}
+
+	if _, err := atomic.Write([]byte("abc")); err == nil {
+		t.Errorf("expected an error writing into a missing directory")
+	}
 }